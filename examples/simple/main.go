@@ -21,8 +21,8 @@ type (
 )
 
 func main() {
-	requestParser, doneFunc := typedhandler.CreateParser[*LoginRequest]()
-	handler := typedhandler.CreateHandler(requestParser, doneFunc, serviceFunc)
+	requestParser, releaseFunc := typedhandler.CreateParser[*LoginRequest]()
+	handler := typedhandler.CreateHandler(requestParser, releaseFunc, serviceFunc)
 	http.HandleFunc("POST /login", handler)
 
 	if err := http.ListenAndServe(":8000", http.DefaultServeMux); err != nil { //nolint: gosec
@@ -0,0 +1,310 @@
+package typedhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mailru/easyjson"
+	ejbuffer "github.com/mailru/easyjson/buffer"
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+type (
+	// BodyCodec decodes a request body into a value
+	BodyCodec interface {
+		Decode(r io.Reader, v any) error
+	}
+
+	// JSONCodec decodes and encodes JSON bodies with encoding/json
+	JSONCodec struct{}
+
+	// XMLCodec decodes and encodes XML bodies with encoding/xml
+	XMLCodec struct{}
+
+	// RawCodec passes the body through, without decoding, into a *[]byte or a *io.Reader.
+	// Other targets can't receive the body, and fail with an UnsupportedMediaTypeError
+	RawCodec struct{}
+
+	// bodyCodecFunc returns the codec for the media type of a request body
+	bodyCodecFunc func(mediaType string) (BodyCodec, error)
+
+	// jsonWriter encodes the responses of the easyjson fast path, keeping its chunk between the encodings
+	jsonWriter struct {
+		writer jwriter.Writer
+		chunk  []byte // chunk of the last encoding, owned by the jsonWriter
+	}
+
+	// UnsupportedMediaTypeError is returned when there is no BodyCodec for the Content-Type of the request
+	UnsupportedMediaTypeError struct {
+		MediaType string
+	}
+)
+
+const (
+	jsonMediaType        = "application/json"
+	xmlMediaType         = "application/xml"
+	textXMLMediaType     = "text/xml"
+	octetStreamMediaType = "application/octet-stream"
+)
+
+var (
+	bodyCodecs = map[string]BodyCodec{
+		jsonMediaType:        JSONCodec{},
+		xmlMediaType:         XMLCodec{},
+		textXMLMediaType:     XMLCodec{},
+		octetStreamMediaType: RawCodec{},
+	}
+	bodyCodecsLock sync.RWMutex
+
+	bytesType  = reflect.TypeFor[[]byte]()
+	readerType = reflect.TypeFor[io.Reader]()
+
+	easyjsonUnmarshalerType = reflect.TypeFor[easyjson.Unmarshaler]()
+	easyjsonMarshalerType   = reflect.TypeFor[easyjson.Marshaler]()
+	jsonUnmarshalerType     = reflect.TypeFor[json.Unmarshaler]()
+	xmlMarshalerType        = reflect.TypeFor[xml.Marshaler]()
+
+	xmlEncodableTypes sync.Map // reflect.Type -> bool
+
+	lexerPool = sync.Pool{
+		New: func() any { return new(jlexer.Lexer) },
+	}
+	jsonWriterPool = sync.Pool{
+		New: func() any { return new(jsonWriter) },
+	}
+
+	nullJSON = []byte("null")
+)
+
+// RegisterBodyCodec registers the codec used to decode request bodies with the media type (e.g. "application/yaml")
+// A registered codec replaces the previous codec for the same media type
+func RegisterBodyCodec(mediaType string, codec BodyCodec) {
+	bodyCodecsLock.Lock()
+	defer bodyCodecsLock.Unlock()
+
+	bodyCodecs[strings.ToLower(mediaType)] = codec
+}
+
+// getBodyCodec returns the codec for the media type
+// Requests without a Content-Type are decoded as JSON, and structured syntax suffixes
+// ("application/problem+json", "application/atom+xml") fall back to the JSON and XML codecs
+func getBodyCodec(mediaType string) (BodyCodec, error) {
+	if mediaType == "" {
+		mediaType = jsonMediaType
+	}
+
+	bodyCodecsLock.RLock()
+	defer bodyCodecsLock.RUnlock()
+
+	if codec, ok := bodyCodecs[mediaType]; ok {
+		return codec, nil
+	}
+
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return bodyCodecs[jsonMediaType], nil
+	case strings.HasSuffix(mediaType, "+xml"):
+		return bodyCodecs[xmlMediaType], nil
+	}
+
+	return nil, UnsupportedMediaTypeError{MediaType: mediaType}
+}
+
+// jsonFastDecoder returns a function that decodes JSON into values of type t with their
+// easyjson.Unmarshaler or json.Unmarshaler implementation, or nil if t implements none of them
+func jsonFastDecoder(t reflect.Type) func(r io.Reader, v any) error {
+	switch {
+	case t.Implements(easyjsonUnmarshalerType):
+		return func(r io.Reader, v any) error {
+			buffer, err := readPooled(r)
+			defer putBuffer(buffer)
+
+			if err != nil {
+				return err
+			}
+
+			lexer := lexerPool.Get().(*jlexer.Lexer)
+			defer lexerPool.Put(lexer)
+
+			*lexer = jlexer.Lexer{Data: buffer.Bytes()}
+			v.(easyjson.Unmarshaler).UnmarshalEasyJSON(lexer)
+
+			err = lexer.Error()
+			*lexer = jlexer.Lexer{} // releases the buffer
+
+			return err
+		}
+	case t.Implements(jsonUnmarshalerType):
+		return func(r io.Reader, v any) error {
+			buffer, err := readPooled(r)
+			defer putBuffer(buffer)
+
+			if err != nil {
+				return err
+			}
+
+			return v.(json.Unmarshaler).UnmarshalJSON(buffer.Bytes())
+		}
+	default:
+		return nil
+	}
+}
+
+// readPooled reads r into a pooled buffer, that must be returned with putBuffer
+func readPooled(r io.Reader) (*bytes.Buffer, error) {
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	_, err := buffer.ReadFrom(r)
+
+	return buffer, err
+}
+
+// jsonFastEncoder returns a function that encodes values of type T as JSON with their
+// easyjson.Marshaler implementation, or nil if T does not implement it
+func jsonFastEncoder[T any]() func(w io.Writer, v T) error {
+	if !reflect.TypeFor[T]().Implements(easyjsonMarshalerType) {
+		return nil
+	}
+
+	nullable := reflect.TypeFor[T]().Kind() == reflect.Pointer
+
+	return func(w io.Writer, v T) error {
+		marshaler := any(v).(easyjson.Marshaler)
+		if nullable && reflect.ValueOf(marshaler).IsNil() {
+			_, err := w.Write(nullJSON)
+			return err
+		}
+
+		pooled := jsonWriterPool.Get().(*jsonWriter)
+		defer jsonWriterPool.Put(pooled)
+
+		return pooled.encode(w, marshaler)
+	}
+}
+
+// encode writes the JSON of the marshaler into w, reusing the chunk of the previous encoding when the JSON fits
+// in a single chunk. Larger JSONs are written into the chunks of easyjson, that take over the previous chunk
+func (p *jsonWriter) encode(w io.Writer, marshaler easyjson.Marshaler) error {
+	p.writer = jwriter.Writer{Buffer: ejbuffer.Buffer{Buf: p.chunk[:0]}}
+	marshaler.MarshalEasyJSON(&p.writer)
+
+	singleChunk := p.writer.Size() == len(p.writer.Buffer.Buf)
+
+	data, err := p.writer.BuildBytes()
+	if err != nil {
+		return err
+	}
+
+	p.chunk = nil
+	if singleChunk {
+		p.chunk = data
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// isXMLEncodable checks if values of type t can be encoded by encoding/xml, caching the result by type
+func isXMLEncodable(t reflect.Type) bool {
+	if t == nil {
+		return true
+	}
+
+	if encodable, ok := xmlEncodableTypes.Load(t); ok {
+		return encodable.(bool)
+	}
+
+	encodable := xmlEncodable(t, make(map[reflect.Type]struct{}))
+	xmlEncodableTypes.Store(t, encodable)
+
+	return encodable
+}
+
+// xmlEncodable checks if values of type t can be encoded by encoding/xml, visiting each type once
+func xmlEncodable(t reflect.Type, visited map[reflect.Type]struct{}) bool {
+	if _, ok := visited[t]; ok || t.Implements(xmlMarshalerType) || reflect.PointerTo(t).Implements(xmlMarshalerType) {
+		return true
+	}
+
+	visited[t] = struct{}{}
+
+	switch t.Kind() {
+	case reflect.Map, reflect.Func, reflect.Chan:
+		return false
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return xmlEncodable(t.Elem(), visited)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if field.IsExported() && field.Tag.Get("xml") != "-" && !xmlEncodable(field.Type, visited) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isRawBody checks if the body is passed through to the field of type t, without decoding
+func isRawBody(t reflect.Type) bool {
+	return t == bytesType || t == readerType
+}
+
+func (JSONCodec) CanEncode(any) bool {
+	return true
+}
+
+func (JSONCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+func (JSONCodec) Encode(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err == nil {
+		_, err = w.Write(data)
+	}
+
+	return err
+}
+
+// CanEncode checks if encoding/xml can encode the value: maps, funcs and chans, and the structs, slices,
+// arrays and pointers that hold them, are rejected, so the negotiation falls back to another encoder
+func (XMLCodec) CanEncode(v any) bool {
+	return isXMLEncodable(reflect.TypeOf(v))
+}
+
+func (XMLCodec) Decode(r io.Reader, v any) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+func (XMLCodec) Encode(w io.Writer, v any) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+func (RawCodec) Decode(r io.Reader, v any) (err error) {
+	switch target := v.(type) {
+	case *[]byte:
+		*target, err = io.ReadAll(r)
+	case *io.Reader:
+		*target = r
+	default:
+		err = UnsupportedMediaTypeError{MediaType: octetStreamMediaType}
+	}
+
+	return err
+}
+
+func (e UnsupportedMediaTypeError) Error() string {
+	return "unsupported media type: " + e.MediaType
+}
+
+func (e UnsupportedMediaTypeError) Status() int {
+	return http.StatusUnsupportedMediaType
+}
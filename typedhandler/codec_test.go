@@ -0,0 +1,323 @@
+package typedhandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/guionardo/typedhandler/examples/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	partnerRequest struct {
+		Name string `json:"name" xml:"name"`
+		City string `json:"city" xml:"city"`
+	}
+	rawBytesRequest struct {
+		ID   string `path:"id"`
+		Data []byte `body:"data"`
+	}
+	rawReaderRequest struct {
+		Data io.Reader `body:"data"`
+	}
+	upperCodec struct{}
+	// customJsonRequest decodes a JSON string into Name
+	customJsonRequest struct {
+		Name string `json:"name"`
+	}
+)
+
+func (r *customJsonRequest) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &r.Name)
+}
+
+func (r *rawBytesRequest) GetBodyField() any {
+	return &r.Data
+}
+
+func (r *rawReaderRequest) GetBodyField() any {
+	return &r.Data
+}
+
+func (upperCodec) Decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err == nil {
+		err = json.Unmarshal(bytes.ToUpper(data), v)
+	}
+
+	return err
+}
+
+func Test_getBodyCodec(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		mediaType string
+		want      BodyCodec
+	}{
+		{"", JSONCodec{}},
+		{"application/json", JSONCodec{}},
+		{"application/merge-patch+json", JSONCodec{}},
+		{"application/xml", XMLCodec{}},
+		{"text/xml", XMLCodec{}},
+		{"application/atom+xml", XMLCodec{}},
+		{"application/octet-stream", RawCodec{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := getBodyCodec(tt.mediaType)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := getBodyCodec("text/csv")
+
+		var unsupported UnsupportedMediaTypeError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, http.StatusUnsupportedMediaType, unsupported.Status())
+	})
+}
+
+func TestRegisterBodyCodec(t *testing.T) {
+	t.Parallel()
+
+	RegisterBodyCodec("Application/X-Upper", upperCodec{})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"john"}`))
+	req.Header.Set("Content-Type", "application/x-upper")
+
+	var body struct {
+		Name string `json:"NAME"`
+	}
+
+	require.NoError(t, parseBodyInstance(req, &body, getBodyCodec))
+	assert.Equal(t, "JOHN", body.Name)
+}
+
+func TestRawCodec_Decode(t *testing.T) {
+	t.Parallel()
+
+	var invalid string
+
+	var unsupported UnsupportedMediaTypeError
+	require.ErrorAs(t, RawCodec{}.Decode(strings.NewReader("data"), &invalid), &unsupported)
+	assert.Equal(t, "application/octet-stream", unsupported.MediaType)
+}
+
+func TestXMLCodec_CanEncode(t *testing.T) {
+	t.Parallel()
+
+	type (
+		labeled struct {
+			Name   string
+			Labels map[string]string
+		}
+		ignored struct {
+			Name   string
+			Labels map[string]string `xml:"-"`
+		}
+		tree struct {
+			Children []*tree
+		}
+	)
+
+	assert.True(t, XMLCodec{}.CanEncode(partnerRequest{}))
+	assert.True(t, XMLCodec{}.CanEncode(&ignored{}))
+	assert.True(t, XMLCodec{}.CanEncode(tree{}))
+	assert.True(t, XMLCodec{}.CanEncode(nil))
+	assert.False(t, XMLCodec{}.CanEncode(map[string]string{}))
+	assert.False(t, XMLCodec{}.CanEncode([]map[string]string{}))
+	assert.False(t, XMLCodec{}.CanEncode(&labeled{}))
+	assert.False(t, XMLCodec{}.CanEncode(func() {}))
+}
+
+func TestCreateSimpleHandler_bodyCodecs(t *testing.T) {
+	t.Parallel()
+
+	handler := CreateSimpleHandler(func(ctx context.Context, request *partnerRequest) (string, int, error) {
+		return request.Name + "/" + request.City, http.StatusOK, nil
+	})
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"john","city":"rio"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		handler(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `"john/rio"`, w.Body.String())
+	})
+	t.Run("xml", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/",
+			strings.NewReader(`<partnerRequest><name>john</name><city>rio</city></partnerRequest>`))
+		req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+
+		w := httptest.NewRecorder()
+		handler(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `"john/rio"`, w.Body.String())
+	})
+	t.Run("octet_stream", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"john","city":"rio"}`))
+		req.Header.Set("Content-Type", "application/octet-stream")
+
+		w := httptest.NewRecorder()
+		handler(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Equal(t, "unsupported media type: application/octet-stream", w.Body.String())
+	})
+	t.Run("unsupported_media_type", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`name,city`))
+		req.Header.Set("Content-Type", "text/csv")
+
+		w := httptest.NewRecorder()
+		handler(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+}
+
+func TestCreateSimpleHandler_rawBody(t *testing.T) {
+	t.Parallel()
+	t.Run("bytes", func(t *testing.T) {
+		t.Parallel()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("PUT /blobs/{id}", CreateSimpleHandler(
+			func(ctx context.Context, request *rawBytesRequest) (string, int, error) {
+				return request.ID + ":" + string(request.Data), http.StatusOK, nil
+			}))
+
+		req := httptest.NewRequest(http.MethodPut, "/blobs/1", strings.NewReader("any content"))
+		req.Header.Set("Content-Type", "image/png")
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `"1:any content"`, w.Body.String())
+	})
+	t.Run("reader", func(t *testing.T) {
+		t.Parallel()
+
+		handler := CreateSimpleHandler(func(ctx context.Context, request *rawReaderRequest) (string, int, error) {
+			data, err := io.ReadAll(request.Data)
+			return string(data), http.StatusOK, err
+		})
+
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("streamed")))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `"streamed"`, w.Body.String())
+	})
+}
+
+func Test_jsonFastDecoder(t *testing.T) {
+	t.Parallel()
+	t.Run("easyjson", func(t *testing.T) {
+		t.Parallel()
+
+		decode := jsonFastDecoder(reflect.TypeFor[*sample.Request]())
+		require.NotNil(t, decode)
+
+		var request sample.Request
+		require.NoError(t, decode(strings.NewReader(`{"name":"john","age":30}`), &request))
+		assert.Equal(t, "john", request.Name)
+		assert.Equal(t, 30, request.Age)
+	})
+	t.Run("json_unmarshaler", func(t *testing.T) {
+		t.Parallel()
+
+		decode := jsonFastDecoder(reflect.TypeFor[*customJsonRequest]())
+		require.NotNil(t, decode)
+
+		var request customJsonRequest
+		require.NoError(t, decode(strings.NewReader(`"john"`), &request))
+		assert.Equal(t, "john", request.Name)
+	})
+	t.Run("none", func(t *testing.T) {
+		t.Parallel()
+		assert.Nil(t, jsonFastDecoder(reflect.TypeFor[*partnerRequest]()))
+	})
+}
+
+func Test_jsonFastEncoder(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, jsonFastEncoder[sample.Response]())
+
+	encode := jsonFastEncoder[sample.Request]()
+	require.NotNil(t, encode)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, encode(buffer, sample.Request{Name: "john"}))
+	assert.Contains(t, buffer.String(), `"name":"john"`)
+
+	t.Run("large", func(t *testing.T) {
+		t.Parallel()
+
+		// larger than the first chunk, and encoded again to reuse the chunks
+		name := strings.Repeat("j", 4096)
+		for range 2 {
+			buffer := &bytes.Buffer{}
+			require.NoError(t, encode(buffer, sample.Request{Name: name, City: "rio"}))
+
+			var request sample.Request
+			require.NoError(t, json.Unmarshal(buffer.Bytes(), &request))
+			assert.Equal(t, name, request.Name)
+			assert.Equal(t, "rio", request.City)
+		}
+	})
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		buffer := &bytes.Buffer{}
+		require.NoError(t, jsonFastEncoder[*sample.Request]()(buffer, nil))
+		assert.Equal(t, "null", buffer.String())
+	})
+}
+
+func TestCreateSimpleHandler_jsonFastPath(t *testing.T) {
+	t.Parallel()
+
+	handler := CreateSimpleHandler(func(ctx context.Context, request *customJsonRequest) (string, int, error) {
+		return request.Name, http.StatusOK, nil
+	})
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`"john"`)))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `"john"`, w.Body.String())
+	})
+	t.Run("other_codecs_are_not_bypassed", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`"john"`))
+		req.Header.Set("Content-Type", "text/csv")
+
+		w := httptest.NewRecorder()
+		handler(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+}
@@ -0,0 +1,313 @@
+package typedhandler
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type (
+	// Config holds the settings of the parsers and handlers created with it, instead of the package-level settings.
+	// The settings that are not set fall back to the package-level ones (PoolEnabled, SetTimeLayouts,
+	// RegisterBodyCodec, RegisterResponseEncoder, AggregateParseErrors, ProblemDetailsEnabled, ...).
+	// A Config can be shared by a group of routes, and extended by the routes that need other settings:
+	//
+	//	api := typedhandler.NewConfig(typedhandler.WithMaxBodySize(1<<20), typedhandler.WithErrorRenderer(renderError))
+	//	uploads := api.With(typedhandler.WithMaxBodySize(32<<20))
+	//
+	//	mux.HandleFunc("POST /users", typedhandler.CreateSimpleHandler(createUser, typedhandler.WithConfig(api)))
+	//	mux.HandleFunc("POST /files", typedhandler.CreateSimpleHandler(upload, typedhandler.WithConfig(uploads)))
+	Config struct {
+		pool               *bool                   // instances are reused from a pool
+		timeLayouts        []string                // layouts of the time values
+		validator          StructValidator         // validator of the request schemas
+		maxBodySize        int64                   // max size of the request bodies, 0 for no limit
+		errorRenderer      ErrorRenderer           // writes the error responses
+		bodyCodecs         map[string]BodyCodec    // request body codecs by media type
+		responseEncoders   []registeredEncoder     // response encoders, preferred over the registered ones
+		aggregateErrors    *bool                   // all the errors of a request are collected into RequestErrors
+		pathErrorStatus    int                     // status of the ParseErrors of path values, 0 for PathErrorStatus
+		problemDetails     *bool                   // the error responses are written as problem details
+		validationRenderer ValidationErrorRenderer // writes the validation errors
+		translator         *Translator             // translates the validation messages
+		route              *routeOption            // route recorded when the handler is created
+	}
+
+	// routeOption is the route of a handler set by WithRoute
+	routeOption struct {
+		registry *Registry
+		pattern  string
+	}
+
+	// Option sets a setting of a Config
+	Option func(config *Config)
+
+	// ErrorRenderer writes the response of a request that failed with a non-nil error
+	// (parse, PreParse, validation and service errors)
+	ErrorRenderer func(w http.ResponseWriter, r *http.Request, err error)
+)
+
+// NewConfig creates a Config with the options
+func NewConfig(options ...Option) *Config {
+	config := &Config{}
+	for _, option := range options {
+		option(config)
+	}
+
+	return config
+}
+
+// With creates a copy of the config with the options, leaving the config unchanged
+func (c *Config) With(options ...Option) *Config {
+	return NewConfig(append([]Option{WithConfig(c)}, options...)...)
+}
+
+// WithConfig sets the settings of the config. The options that follow it override its settings
+func WithConfig(config *Config) Option {
+	return func(c *Config) {
+		if config != nil {
+			*c = *config // the options copy the codecs and encoders before changing them
+		}
+	}
+}
+
+// WithPool enables or disables the reuse of the request instances from a pool, instead of PoolEnabled
+func WithPool(enabled bool) Option {
+	return func(c *Config) {
+		c.pool = &enabled
+	}
+}
+
+// WithTimeLayouts sets the layouts used to parse the time values, instead of the layouts set by SetTimeLayouts
+func WithTimeLayouts(layouts ...string) Option {
+	return func(c *Config) {
+		c.timeLayouts = slices.Clone(layouts)
+	}
+}
+
+// WithValidator sets the validator of the request schemas, instead of the validator shared by the package (Validator).
+// A *validator.Validate receives the translations of SetTranslator and validates the values of the Optional fields.
+// Share the validator between the handlers (e.g. with a Config), so they share its caches:
+//
+//	v := typedhandler.NewValidator()
+//	_ = v.RegisterValidation("cpf", validateCPF)
+//	api := typedhandler.NewConfig(typedhandler.WithValidator(v))
+func WithValidator(v StructValidator) Option {
+	return func(c *Config) {
+		c.validator = v
+	}
+}
+
+// WithMaxBodySize limits the size of the request bodies to maxBytes.
+// Larger bodies fail with a BodyTooLargeError (413 Request Entity Too Large)
+func WithMaxBodySize(maxBytes int64) Option {
+	return func(c *Config) {
+		c.maxBodySize = maxBytes
+	}
+}
+
+// WithErrorRenderer sets the func that writes the error responses, instead of the default error responses
+// and problem details
+func WithErrorRenderer(renderer ErrorRenderer) Option {
+	return func(c *Config) {
+		c.errorRenderer = renderer
+	}
+}
+
+// WithAggregateErrors enables or disables the collection of all the errors of a request into RequestErrors,
+// instead of AggregateParseErrors
+func WithAggregateErrors(enabled bool) Option {
+	return func(c *Config) {
+		c.aggregateErrors = &enabled
+	}
+}
+
+// WithPathErrorStatus sets the status of the ParseErrors of path values, instead of PathErrorStatus
+func WithPathErrorStatus(status int) Option {
+	return func(c *Config) {
+		c.pathErrorStatus = status
+	}
+}
+
+// WithProblemDetails enables or disables the problem details error responses, instead of ProblemDetailsEnabled
+func WithProblemDetails(enabled bool) Option {
+	return func(c *Config) {
+		c.problemDetails = &enabled
+	}
+}
+
+// WithValidationErrorRenderer sets the renderer of the validation errors, instead of the renderer set by
+// SetValidationErrorRenderer
+func WithValidationErrorRenderer(renderer ValidationErrorRenderer) Option {
+	return func(c *Config) {
+		c.validationRenderer = renderer
+	}
+}
+
+// WithTranslator sets the translator of the validation messages, instead of the translator set by SetTranslator.
+// The parsers created with the config register its translations into their validator, so pass it to the parser
+// and to the handler (e.g. with CreateSimpleHandler or a Config)
+func WithTranslator(translator *Translator) Option {
+	return func(c *Config) {
+		c.translator = translator
+	}
+}
+
+// WithRoute records the route of the handler in the registry when it is created, like Register.
+// The pattern is the one used to register the handler in the http.ServeMux. It is an option of a single handler:
+// a Config with a route records it for each of its handlers
+//
+//	mux.HandleFunc("GET /users/{id}", typedhandler.CreateSimpleHandler(getUser,
+//		typedhandler.WithRoute(registry, "GET /users/{id}")))
+func WithRoute(registry *Registry, pattern string) Option {
+	return func(c *Config) {
+		c.route = &routeOption{registry: registry, pattern: pattern}
+	}
+}
+
+// WithBodyCodec sets the codec used to decode the request bodies with the media type,
+// taking precedence over the codecs registered by RegisterBodyCodec
+func WithBodyCodec(mediaType string, codec BodyCodec) Option {
+	return func(c *Config) {
+		c.bodyCodecs = maps.Clone(c.bodyCodecs)
+		if c.bodyCodecs == nil {
+			c.bodyCodecs = make(map[string]BodyCodec)
+		}
+
+		c.bodyCodecs[strings.ToLower(mediaType)] = codec
+	}
+}
+
+// WithResponseEncoder sets the encoder used for the responses with the content type,
+// preferred over the encoders registered by RegisterResponseEncoder
+func WithResponseEncoder(contentType string, encoder ResponseEncoder) Option {
+	return func(c *Config) {
+		c.responseEncoders = setEncoder(slices.Clone(c.responseEncoders), newRegisteredEncoder(contentType, encoder))
+	}
+}
+
+// poolEnabled returns true if the instances are reused from a pool
+func (c *Config) poolEnabled() bool {
+	if c.pool != nil {
+		return *c.pool
+	}
+
+	return PoolEnabled
+}
+
+// aggregateParseErrors returns true if all the errors of a request are collected into RequestErrors
+func (c *Config) aggregateParseErrors() bool {
+	if c.aggregateErrors != nil {
+		return *c.aggregateErrors
+	}
+
+	return AggregateParseErrors
+}
+
+// problemDetailsEnabled returns true if the error responses are written as problem details
+func (c *Config) problemDetailsEnabled() bool {
+	if c.problemDetails != nil {
+		return *c.problemDetails
+	}
+
+	return ProblemDetailsEnabled
+}
+
+// timeParser returns the parser of the time values, with the layouts of the config or the package-level layouts
+func (c *Config) timeParser() timeParser {
+	if c.timeLayouts != nil {
+		return newTimeParser(c.timeLayouts)
+	}
+
+	return ParseTime
+}
+
+// structValidator returns the validator of the config or the validator shared by the package
+func (c *Config) structValidator() StructValidator {
+	if c.validator != nil {
+		return c.validator
+	}
+
+	return getValidator()
+}
+
+// playgroundValidator returns the validator of the config, if it is a *validator.Validate, or nil
+func (c *Config) playgroundValidator() *validator.Validate {
+	v, _ := c.structValidator().(*validator.Validate)
+	return v
+}
+
+// bodyCodec returns the codec of the config for the media type, or the registered codec
+func (c *Config) bodyCodec(mediaType string) (BodyCodec, error) {
+	if codec, ok := c.bodyCodecs[mediaType]; ok {
+		return codec, nil
+	}
+
+	return getBodyCodec(mediaType)
+}
+
+// limitBody limits the size of the request body to the max body size of the config, if any
+func (c *Config) limitBody(r *http.Request) {
+	if c.maxBodySize > 0 && r.Body != nil && r.Body != http.NoBody {
+		r.Body = http.MaxBytesReader(nil, r.Body, c.maxBodySize)
+	}
+}
+
+// errorWriter returns the func that writes the error responses: the error renderer of the config,
+// or the default error responses with the settings of the config. The func ignores nil errors
+// The requests carry the translator of the config to the renderers (see NewValidationErrorResponse)
+func (c *Config) errorWriter() ErrorRenderer {
+	if c.errorRenderer == nil && c.translator == nil {
+		return c.writeErrorResponse
+	}
+
+	renderer := c.errorRenderer
+	if renderer == nil {
+		renderer = c.writeErrorResponse
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		if err != nil {
+			renderer(w, withTranslator(r, c.translator), err)
+		}
+	}
+}
+
+// schemaKey returns the key of the settings of the config used by the schema helpers, empty for the default settings
+// The validator, the body codecs and the translator are identified by their type and value (or address, for the
+// reference types), so configs created with the same options share the key, and the helper
+func (c *Config) schemaKey() string {
+	if c.pool == nil && c.timeLayouts == nil && c.validator == nil && c.bodyCodecs == nil &&
+		c.aggregateErrors == nil && c.pathErrorStatus == 0 && c.translator == nil {
+		return ""
+	}
+
+	codecs := make([]string, 0, len(c.bodyCodecs))
+	for _, mediaType := range slices.Sorted(maps.Keys(c.bodyCodecs)) {
+		codecs = append(codecs, mediaType+"="+settingKey(c.bodyCodecs[mediaType]))
+	}
+
+	return fmt.Sprintf("pool=%v,layouts=%q,validator=%s,codecs=%q,aggregate=%v,pathStatus=%d,translator=%p",
+		c.poolEnabled(), c.timeLayouts, settingKey(c.validator), codecs, c.aggregateParseErrors(), c.pathErrorStatus,
+		c.translator)
+}
+
+// settingKey identifies a setting of the config: by its type and address for the reference types (pointers, maps,
+// funcs, ...), or by its type and value for the other types
+func settingKey(setting any) string {
+	value := reflect.ValueOf(setting)
+
+	switch value.Kind() {
+	case reflect.Invalid:
+		return "nil"
+	case reflect.Pointer, reflect.Map, reflect.Func, reflect.Chan, reflect.Slice, reflect.UnsafePointer:
+		return fmt.Sprintf("%T@%x", setting, value.Pointer())
+	default:
+		return fmt.Sprintf("%T%#v", setting, setting)
+	}
+}
@@ -0,0 +1,323 @@
+package typedhandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	configRequest struct {
+		Name string    `json:"name" validate:"required"`
+		Date time.Time `json:"-"    query:"date"`
+	}
+	configEvenRequest struct {
+		Number int `query:"number" validate:"even"`
+	}
+	configOddRequest struct {
+		Number int `query:"number" validate:"configodd"`
+	}
+	// configStructValidator rejects every request, like an adapter of another validation library
+	configStructValidator struct{}
+	// configEncoder encodes the responses as "name=<value>"
+	configEncoder struct{}
+)
+
+func (configEncoder) CanEncode(v any) bool {
+	_, ok := v.(*configRequest)
+	return ok
+}
+
+func (configEncoder) Encode(w io.Writer, v any) error {
+	_, err := fmt.Fprintf(w, "name=%s", v.(*configRequest).Name)
+	return err
+}
+
+func (configStructValidator) Struct(any) error {
+	return httpError{StatusCode: http.StatusUnprocessableEntity, Message: "rejected"}
+}
+
+func (v configStructValidator) StructExcept(s any, _ ...string) error {
+	return v.Struct(s)
+}
+
+func echoConfigRequest(_ context.Context, request *configRequest) (*configRequest, int, error) {
+	return request, http.StatusOK, nil
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	base := NewConfig(WithMaxBodySize(10), WithTimeLayouts("02/01/2006"))
+	extended := base.With(WithMaxBodySize(20), WithBodyCodec("text/plain", upperCodec{}))
+
+	assert.Equal(t, int64(10), base.maxBodySize)
+	assert.Nil(t, base.bodyCodecs)
+	assert.Equal(t, int64(20), extended.maxBodySize)
+	assert.Equal(t, []string{"02/01/2006"}, extended.timeLayouts)
+	assert.Equal(t, upperCodec{}, extended.bodyCodecs["text/plain"])
+
+	overridden := NewConfig(WithConfig(extended), WithTimeLayouts(time.DateOnly))
+	assert.Equal(t, []string{time.DateOnly}, overridden.timeLayouts)
+	assert.Equal(t, []string{"02/01/2006"}, extended.timeLayouts)
+}
+
+func TestGetSchemaHelper_options(t *testing.T) {
+	t.Parallel()
+
+	config := NewConfig(WithPool(false), WithTimeLayouts("02/01/2006"))
+	helper := GetSchemaHelper[*configRequest](WithConfig(config))
+
+	assert.Same(t, GetSchemaHelper[*configRequest](), GetSchemaHelper[*configRequest]())
+	assert.NotSame(t, GetSchemaHelper[*configRequest](), helper)
+	assert.Same(t, helper, GetSchemaHelper[*configRequest](WithConfig(config)))
+	assert.Same(t, helper, GetSchemaHelper[*configRequest](WithPool(false), WithTimeLayouts("02/01/2006")))
+	assert.Same(t, helper, GetSchemaHelper[*configRequest](WithConfig(config), WithMaxBodySize(1)),
+		"the body size is a setting of the parser")
+	assert.NotSame(t, helper, GetSchemaHelper[*configRequest](WithConfig(config), WithPool(true)))
+
+	codecs := GetSchemaHelper[*configRequest](WithBodyCodec("text/plain", upperCodec{}),
+		WithBodyCodec("text/x", JSONCodec{}))
+	assert.Same(t, codecs, GetSchemaHelper[*configRequest](WithBodyCodec("text/x", JSONCodec{}),
+		WithBodyCodec("text/plain", upperCodec{})), "the codecs are identified by media type and value")
+	assert.NotSame(t, codecs, GetSchemaHelper[*configRequest](WithBodyCodec("text/plain", JSONCodec{})))
+	assert.Same(t, GetSchemaHelper[*configRequest](WithValidator(configStructValidator{})),
+		GetSchemaHelper[*configRequest](WithValidator(configStructValidator{})))
+
+	v := NewValidator()
+	assert.Same(t, GetSchemaHelper[*configRequest](WithValidator(v)), GetSchemaHelper[*configRequest](WithValidator(v)))
+	assert.NotSame(t, GetSchemaHelper[*configRequest](WithValidator(v)),
+		GetSchemaHelper[*configRequest](WithValidator(NewValidator())))
+
+	t.Run("pool_disabled", func(t *testing.T) {
+		t.Parallel()
+
+		instance := helper.GetInstance()
+		helper.PutInstance(instance)
+		assert.NotSame(t, instance, helper.GetInstance())
+	})
+}
+
+func TestCreateParser_options(t *testing.T) {
+	t.Parallel()
+
+	t.Run("time_layouts", func(t *testing.T) {
+		t.Parallel()
+
+		parser, release := CreateParser[*configRequest](WithTimeLayouts("02/01/2006"))
+		r := httptest.NewRequest(http.MethodPost, "/?date=31/12/2025", strings.NewReader(`{"name":"john"}`))
+
+		instance, err := parser(r)
+		defer release(instance)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), instance.Date)
+
+		_, err = parser(httptest.NewRequest(http.MethodPost, "/?date=2025-12-31T00:00:00Z",
+			strings.NewReader(`{"name":"john"}`)))
+		require.ErrorIs(t, err, ErrTimeParsing)
+	})
+	t.Run("validator", func(t *testing.T) {
+		t.Parallel()
+
+		v := NewValidator()
+		require.NoError(t, v.RegisterValidation("even", func(fl validator.FieldLevel) bool {
+			return fl.Field().Int()%2 == 0
+		}))
+
+		parser, release := CreateParser[*configEvenRequest](WithValidator(v))
+
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/?number=2", nil))
+		release(instance)
+		require.NoError(t, err)
+
+		instance, err = parser(httptest.NewRequest(http.MethodGet, "/?number=3", nil))
+		release(instance)
+
+		var validationErrors validator.ValidationErrors
+		require.ErrorAs(t, err, &validationErrors)
+		assert.Equal(t, "even", validationErrors[0].Tag())
+		assert.Equal(t, "number", validationErrors[0].Field())
+	})
+	t.Run("struct_validator", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		CreateSimpleHandler(echoConfigRequest, WithValidator(configStructValidator{}))(w,
+			httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"john"}`)))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "rejected", w.Body.String())
+	})
+	t.Run("body_codec", func(t *testing.T) {
+		t.Parallel()
+
+		parser, release := CreateParser[*configRequest](WithBodyCodec("text/plain", upperCodec{}))
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"NAME":"john"}`))
+		r.Header.Set("Content-Type", "text/plain")
+
+		instance, err := parser(r)
+		defer release(instance)
+
+		require.NoError(t, err)
+		assert.Equal(t, "JOHN", instance.Name)
+	})
+	t.Run("aggregate_errors", func(t *testing.T) {
+		t.Parallel()
+
+		parser, release := CreateParser[*aggregateRequest](WithAggregateErrors(true))
+		r := httptest.NewRequest(http.MethodPost, "/?page=a&limit=b&name=john", strings.NewReader(`{"age":20}`))
+
+		instance, err := parser(r)
+		release(instance)
+
+		var requestErrors RequestErrors
+		require.ErrorAs(t, err, &requestErrors)
+		assert.Len(t, requestErrors, 2)
+
+		parser, release = CreateParser[*aggregateRequest]()
+
+		instance, err = parser(httptest.NewRequest(http.MethodPost, "/?page=a&limit=b&name=john",
+			strings.NewReader(`{"age":20}`)))
+		release(instance)
+
+		require.NotErrorAs(t, err, &requestErrors, "the other parsers stop at the first error")
+	})
+	t.Run("path_error_status", func(t *testing.T) {
+		t.Parallel()
+
+		parser, release := CreateParser[*parseErrorRequest](WithPathErrorStatus(http.StatusNotFound))
+		r := httptest.NewRequest(http.MethodGet, "/?page=1", nil)
+		r.SetPathValue("id", "x")
+
+		instance, err := parser(r)
+		release(instance)
+
+		var parseError ParseError
+		require.ErrorAs(t, err, &parseError)
+		assert.Equal(t, http.StatusNotFound, parseError.Status())
+
+		parser, release = CreateParser[*parseErrorRequest]()
+
+		instance, err = parser(r)
+		release(instance)
+
+		require.ErrorAs(t, err, &parseError)
+		assert.Equal(t, http.StatusBadRequest, parseError.Status())
+	})
+}
+
+func TestCreateHandler_options(t *testing.T) {
+	t.Parallel()
+
+	t.Run("max_body_size", func(t *testing.T) {
+		t.Parallel()
+
+		handler := CreateSimpleHandler(echoConfigRequest, WithMaxBodySize(16))
+
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"john"}`)))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"john doe"}`)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, "request body too large: the limit is 16 bytes", w.Body.String())
+	})
+	t.Run("error_renderer", func(t *testing.T) {
+		t.Parallel()
+
+		handler := CreateSimpleHandler(echoConfigRequest,
+			WithErrorRenderer(func(w http.ResponseWriter, _ *http.Request, err error) {
+				w.WriteHeader(http.StatusTeapot)
+				_, _ = w.Write([]byte("rendered: " + err.Error()))
+			}))
+
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+		assert.Equal(t, http.StatusTeapot, w.Code)
+		assert.True(t, strings.HasPrefix(w.Body.String(), "rendered: "), w.Body.String())
+
+		w = httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"john"}`)))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("response_encoder", func(t *testing.T) {
+		t.Parallel()
+
+		handler := CreateSimpleHandler(echoConfigRequest, WithResponseEncoder("text/x-config", configEncoder{}))
+
+		newRequest := func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"john"}`))
+			r.Header.Set("Accept", "text/x-config")
+
+			return r
+		}
+
+		w := httptest.NewRecorder()
+		handler(w, newRequest())
+		assert.Equal(t, "text/x-config", w.Header().Get("Content-Type"))
+		assert.Equal(t, "name=john", w.Body.String())
+
+		w = httptest.NewRecorder()
+		CreateSimpleHandler(echoConfigRequest)(w, newRequest())
+		assert.Equal(t, http.StatusNotAcceptable, w.Code, "the encoder is not registered for the other handlers")
+	})
+	t.Run("problem_details", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		CreateSimpleHandler(echoConfigRequest, WithProblemDetails(true))(w,
+			httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, problemMediaType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"instance":"/users"`)
+
+		w = httptest.NewRecorder()
+		CreateSimpleHandler(echoConfigRequest)(w, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`)))
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+	t.Run("validation_error_renderer", func(t *testing.T) {
+		t.Parallel()
+
+		handler := CreateSimpleHandler(echoConfigRequest,
+			WithValidationErrorRenderer(func(w http.ResponseWriter, _ *http.Request, errs validator.ValidationErrors) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(errs[0].Tag()))
+			}))
+
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "required", w.Body.String())
+	})
+}
+
+func TestValidator(t *testing.T) { //nolint:paralleltest // registers a validation into the shared validator
+	require.NoError(t, Validator().RegisterValidation("configodd", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 1
+	}))
+
+	parser, release := CreateParser[*configOddRequest]()
+
+	instance, err := parser(httptest.NewRequest(http.MethodGet, "/?number=1", nil))
+	release(instance)
+	require.NoError(t, err)
+
+	instance, err = parser(httptest.NewRequest(http.MethodGet, "/?number=2", nil))
+	release(instance)
+
+	var validationErrors validator.ValidationErrors
+	require.ErrorAs(t, err, &validationErrors)
+	assert.Equal(t, "configodd", validationErrors[0].Tag())
+}
@@ -0,0 +1,194 @@
+package typedhandler
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type (
+	// valueConverter converts a raw value of the request and sets it into the field
+	valueConverter func(data string, field reflect.Value) error
+
+	// valuesConverter converts the raw values of the request and sets them into the field
+	valuesConverter func(values []string, field reflect.Value) error
+)
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+	converters     = make(map[reflect.Type]valueConverter)
+	convertersLock sync.RWMutex
+)
+
+// RegisterConverter registers the func that converts the request values (path, query, header, cookie
+// and form values) into fields of type T, and into the elements of slices, arrays, pointers and Optionals of T.
+// Registered converters take precedence over the built-in conversions and encoding.TextUnmarshaler:
+//
+//	typedhandler.RegisterConverter(uuid.Parse)
+//
+// The converters are resolved when the schema of a request type is created,
+// so they must be registered before the handlers and parsers of the types that use them
+func RegisterConverter[T any](convert func(value string) (T, error)) {
+	convertersLock.Lock()
+	defer convertersLock.Unlock()
+
+	converters[reflect.TypeFor[T]()] = func(data string, field reflect.Value) error {
+		value, err := convert(data)
+		if err == nil {
+			field.Set(reflect.ValueOf(&value).Elem())
+		}
+
+		return err
+	}
+}
+
+// registeredConverter returns the converter registered for the type t, or nil
+func registeredConverter(t reflect.Type) valueConverter {
+	convertersLock.RLock()
+	defer convertersLock.RUnlock()
+
+	return converters[t]
+}
+
+// converterFor returns the converter of single values into fields of type t, or nil if t is not supported:
+// registered converters, time.Time, time.Duration, encoding.TextUnmarshaler implementations, the basic kinds,
+// and pointers and Optionals of them. The time values are parsed by parseTime
+func converterFor(t reflect.Type, parseTime timeParser) valueConverter {
+	if converter := registeredConverter(t); converter != nil {
+		return converter
+	}
+
+	switch {
+	case t == timeType: // before TextUnmarshaler, to accept the configured layouts
+		return timeConverter(parseTime)
+	case t == durationType:
+		return convertDuration
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return convertText
+	}
+
+	if elem := optionalType(t); elem != nil {
+		convert := converterFor(elem, parseTime)
+		if convert == nil {
+			return nil
+		}
+
+		return func(data string, field reflect.Value) error {
+			return convertOptional(field, func(value reflect.Value) error {
+				return convert(data, value)
+			})
+		}
+	}
+
+	return kindConverter(t.Kind())
+}
+
+// valuesConverterFor returns the converter of multiple values into fields of type t:
+// slices receive all the values, arrays receive up to their length, other types receive the first value.
+// Unsupported types return an error when converted
+func valuesConverterFor(t reflect.Type, parseTime timeParser) valuesConverter {
+	if convert := converterFor(t, parseTime); convert != nil {
+		return func(values []string, field reflect.Value) error {
+			return convert(firstValue(values), field)
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		convert := elementConverter(t.Elem(), parseTime)
+
+		return func(values []string, field reflect.Value) error {
+			slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+			if err := convertElements(values, slice, convert); err != nil {
+				return err
+			}
+
+			field.Set(slice)
+
+			return nil
+		}
+
+	case reflect.Array:
+		convert := elementConverter(t.Elem(), parseTime)
+
+		return func(values []string, field reflect.Value) error {
+			if len(values) > field.Len() {
+				return TooManyValuesError{Max: field.Len(), Count: len(values)}
+			}
+
+			array := reflect.New(field.Type()).Elem()
+			if err := convertElements(values, array, convert); err != nil {
+				return err
+			}
+
+			field.Set(array)
+
+			return nil
+		}
+	}
+
+	if elem := optionalType(t); elem != nil {
+		convert := valuesConverterFor(elem, parseTime)
+
+		return func(values []string, field reflect.Value) error {
+			return convertOptional(field, func(value reflect.Value) error {
+				return convert(values, value)
+			})
+		}
+	}
+
+	return func(_ []string, field reflect.Value) error {
+		return unsupportedTypeError(field.Type())
+	}
+}
+
+// elementConverter returns the converter of the elements of slices and arrays of type t.
+// Unsupported types return an error when converted
+func elementConverter(t reflect.Type, parseTime timeParser) valueConverter {
+	if convert := converterFor(t, parseTime); convert != nil {
+		return convert
+	}
+
+	return func(_ string, field reflect.Value) error {
+		return unsupportedTypeError(field.Type())
+	}
+}
+
+// isMultiValued returns true if the fields of type t receive multiple values:
+// slices and arrays (or pointers and Optionals of them) without a converter of single values, like net.IP
+func isMultiValued(t reflect.Type) bool {
+	if converterFor(t, ParseTime) != nil {
+		return false
+	}
+
+	kind := valueType(t).Kind()
+
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+// isConvertible returns true if the request values can be converted into fields of type t:
+// the types with a converter of single values, and slices and arrays of them (or pointers and Optionals of them)
+func isConvertible(t reflect.Type) bool {
+	if converterFor(t, ParseTime) != nil {
+		return true
+	}
+
+	t = valueType(t)
+	if kind := t.Kind(); kind == reflect.Slice || kind == reflect.Array {
+		return converterFor(t.Elem(), ParseTime) != nil
+	}
+
+	return false
+}
+
+// convertText converts the data with the encoding.TextUnmarshaler implemented by the field
+func convertText(data string, field reflect.Value) error {
+	return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(data))
+}
+
+// unsupportedTypeError returns the error of the conversion into a field of type t without a converter
+func unsupportedTypeError(t reflect.Type) error {
+	return fmt.Errorf("unsupported field type: %s", t)
+}
@@ -0,0 +1,149 @@
+package typedhandler
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	converterColor int
+	converterCents struct {
+		Units, Cents int64
+	}
+	converterRequest struct {
+		Color    converterColor           `query:"color"`
+		Colors   []converterColor         `query:"colors,explode=false"`
+		Addr     netip.Addr               `query:"addr"`
+		Addrs    []netip.Addr             `query:"addrs"`
+		IP       net.IP                   `query:"ip"`
+		Callback *url.URL                 `query:"callback"`
+		Price    converterCents           `query:"price"`
+		MaxPrice Optional[converterCents] `query:"max_price"`
+		Origin   Optional[netip.Addr]     `query:"origin"`
+	}
+)
+
+var errInvalidColor = errors.New("invalid color")
+
+func (c *converterColor) UnmarshalText(text []byte) error {
+	colors := map[string]converterColor{"red": 1, "green": 2, "blue": 3}
+
+	color, found := colors[string(text)]
+	if !found {
+		return errInvalidColor
+	}
+
+	*c = color
+
+	return nil
+}
+
+func parseConverterCents(value string) (converterCents, error) {
+	units, cents, _ := strings.Cut(value, ".")
+
+	u, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return converterCents{}, err
+	}
+
+	c, err := strconv.ParseInt(cents, 10, 64)
+	if err != nil && cents != "" {
+		return converterCents{}, err
+	}
+
+	return converterCents{Units: u, Cents: c}, nil
+}
+
+func TestRegisterConverter(t *testing.T) {
+	t.Parallel()
+
+	RegisterConverter(parseConverterCents)
+	RegisterConverter(url.Parse)
+
+	parser, release := CreateParser[*converterRequest]()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/?color=green&colors=red,blue&addr=10.0.0.1&addrs=::1&addrs=10.0.0.2"+
+			"&ip=192.168.0.1&callback=https%3A%2F%2Fexample.com%2Fhook&price=12.50&max_price=20&origin=127.0.0.1", nil)
+
+		instance, err := parser(r)
+		defer release(instance)
+
+		require.NoError(t, err)
+		assert.Equal(t, converterColor(2), instance.Color)
+		assert.Equal(t, []converterColor{1, 3}, instance.Colors)
+		assert.Equal(t, netip.MustParseAddr("10.0.0.1"), instance.Addr)
+		assert.Equal(t, []netip.Addr{netip.MustParseAddr("::1"), netip.MustParseAddr("10.0.0.2")}, instance.Addrs)
+		assert.Equal(t, net.ParseIP("192.168.0.1"), instance.IP)
+		assert.Equal(t, "example.com", instance.Callback.Host)
+		assert.Equal(t, converterCents{Units: 12, Cents: 50}, instance.Price)
+		assert.Equal(t, Some(converterCents{Units: 20}), instance.MaxPrice)
+		assert.Equal(t, Some(netip.MustParseAddr("127.0.0.1")), instance.Origin)
+	})
+	t.Run("text_unmarshaler_error", func(t *testing.T) {
+		t.Parallel()
+
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/?color=pink", nil))
+		defer release(instance)
+
+		var parseError ParseError
+		require.ErrorAs(t, err, &parseError)
+		assert.Equal(t, "color", parseError.Param)
+		require.ErrorIs(t, err, errInvalidColor)
+	})
+	t.Run("registered_converter_error", func(t *testing.T) {
+		t.Parallel()
+
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/?max_price=free", nil))
+		defer release(instance)
+
+		var parseError ParseError
+		require.ErrorAs(t, err, &parseError)
+		assert.Equal(t, "max_price", parseError.Param)
+		assert.False(t, instance.MaxPrice.IsSet())
+	})
+	t.Run("openapi", func(t *testing.T) {
+		t.Parallel()
+
+		g := newSchemaGenerator()
+		for _, field := range []string{"Color", "Addr", "IP", "Callback", "Price", "MaxPrice"} {
+			structField, _ := reflect.TypeFor[converterRequest]().FieldByName(field)
+			assert.Equal(t, &JSONSchema{Type: "string"}, g.paramSchema(structField.Type), field)
+		}
+	})
+}
+
+func Test_converterFor(t *testing.T) {
+	t.Parallel()
+
+	assert.NotNil(t, converterFor(reflect.TypeFor[*netip.Addr](), ParseTime))
+	assert.NotNil(t, converterFor(reflect.TypeFor[Optional[int]](), ParseTime))
+	assert.Nil(t, converterFor(reflect.TypeFor[[]int](), ParseTime))
+	assert.Nil(t, converterFor(reflect.TypeFor[struct{ Name string }](), ParseTime))
+
+	assert.True(t, isMultiValued(reflect.TypeFor[*[]int]()))
+	assert.False(t, isMultiValued(reflect.TypeFor[net.IP]()))
+
+	var unsupported struct{ Name string }
+	require.EqualError(t,
+		valuesConverterFor(reflect.TypeOf(unsupported), ParseTime)([]string{"x"}, reflect.ValueOf(&unsupported).Elem()),
+		"unsupported field type: struct { Name string }")
+
+	var labels map[string]string
+	require.EqualError(t,
+		elementConverter(reflect.TypeOf(labels), ParseTime)("x", reflect.ValueOf(&labels).Elem()),
+		"unsupported field type: map[string]string")
+}
@@ -2,11 +2,20 @@ package typedhandler
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 	"strconv"
 	"time"
 )
 
+type (
+	// TooManyValuesError is returned when a fixed-size array field receives more values than its length
+	TooManyValuesError struct {
+		Max   int // length of the array
+		Count int // number of values received
+	}
+)
+
 const (
 	bit64 = 64
 	bit32 = 32
@@ -17,51 +26,60 @@ const (
 var (
 	timeType     = reflect.TypeFor[time.Time]()
 	durationType = reflect.TypeFor[time.Duration]()
-	TimeFormats  = []string{time.RFC3339, time.RFC3339Nano, time.RFC1123, time.RFC1123Z, time.ANSIC}
+	cookieType   = reflect.TypeFor[*http.Cookie]()
+
+	// Deprecated: TimeFormats is not used to parse the time values. Use SetTimeLayouts or WithTimeLayouts
+	TimeFormats = []string{time.RFC3339, time.RFC3339Nano, time.RFC1123, time.RFC1123Z, time.ANSIC}
 )
 
-// convertData converts the data to the appropriate type and sets it in the struct
-// A query value can be: string, int, uint, float64, bool, time.Time, time.Duration
-func convertData(data string, fieldIndex int, structValue reflect.Value) (err error) {
-	fieldType := structValue.Field(int(fieldIndex)).Type()
-	field := structValue.Field(int(fieldIndex))
-
-	// Handle special types first
-	switch fieldType {
-	case timeType:
-		return convertTime(data, field)
-	case durationType:
-		return convertDuration(data, field)
+func (e TooManyValuesError) Error() string {
+	return fmt.Sprintf("too many values: got %d, accepts at most %d", e.Count, e.Max)
+}
+
+// convertElements converts each value into the element of the slice or array with the same index
+func convertElements(values []string, elements reflect.Value, convert valueConverter) error {
+	for i, value := range values {
+		if err := convert(value, elements.Index(i)); err != nil {
+			return err
+		}
 	}
 
-	// Handle primitive types by kind
-	return convertByKind(data, field)
+	return nil
 }
 
-// convertByKind handles conversion based on the reflect.Kind
-func convertByKind(data string, field reflect.Value) error {
-	kind := field.Kind()
-
+// kindConverter returns the converter of the basic kind, or nil if the kind is not supported
+func kindConverter(kind reflect.Kind) valueConverter {
 	// Group similar types together
 	switch {
 	case kind == reflect.String:
-		field.SetString(data)
-		return nil
+		return convertString
 
 	case kind == reflect.Bool:
-		return convertBool(data, field)
+		return convertBool
 
 	case isIntKind(kind):
-		return convertInt(data, getBitSize(kind), field)
+		bitSize := getBitSize(kind)
+
+		return func(data string, field reflect.Value) error {
+			return convertInt(data, bitSize, field)
+		}
 
 	case isUintKind(kind):
-		return convertUint(data, getBitSize(kind), field)
+		bitSize := getBitSize(kind)
+
+		return func(data string, field reflect.Value) error {
+			return convertUint(data, bitSize, field)
+		}
 
 	case isFloatKind(kind):
-		return convertFloat(data, getBitSize(kind), field)
+		bitSize := getBitSize(kind)
+
+		return func(data string, field reflect.Value) error {
+			return convertFloat(data, bitSize, field)
+		}
 
 	default:
-		return fmt.Errorf("unsupported field type: %s", field.Type().Name())
+		return nil
 	}
 }
 
@@ -100,6 +118,12 @@ func getBitSize(kind reflect.Kind) int {
 	}
 }
 
+// convertString sets the string in the struct field
+func convertString(data string, field reflect.Value) error {
+	field.SetString(data)
+	return nil
+}
+
 // convertBool converts a string to a boolean and sets it in the struct field
 func convertBool(data string, field reflect.Value) (err error) {
 	var boolValue bool
@@ -147,11 +171,14 @@ func convertFloat(data string, bitSize int, field reflect.Value) (err error) {
 	return err
 }
 
-func convertTime(data string, field reflect.Value) (err error) {
-	var timeValue time.Time
-	if timeValue, err = ParseTime(data); err == nil {
-		field.Set(reflect.ValueOf(timeValue))
-	}
+// timeConverter returns the converter of time values parsed by parseTime
+func timeConverter(parseTime timeParser) valueConverter {
+	return func(data string, field reflect.Value) error {
+		timeValue, err := parseTime(data)
+		if err == nil {
+			field.Set(reflect.ValueOf(timeValue))
+		}
 
-	return err
+		return err
+	}
 }
@@ -35,7 +35,7 @@ type TestStruct struct {
 	F64 float64
 }
 
-func Test_convertData(t *testing.T) { //nolint
+func Test_elementConverter(t *testing.T) { //nolint
 	t.Parallel()
 
 	var (
@@ -164,7 +164,8 @@ func Test_convertData(t *testing.T) { //nolint
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			gotErr := convertData(test.data, test.fieldIndex, instanceValue)
+			field := instanceValue.Field(test.fieldIndex)
+			gotErr := elementConverter(field.Type(), ParseTime)(test.data, field)
 			require.NoError(t, gotErr)
 			test.check(t)
 		})
@@ -191,14 +192,11 @@ func valueOfBool() reflect.Value {
 	return reflect.ValueOf(&b).Elem()
 }
 
-func valueOfTime() reflect.Value {
-	var t time.Time
-	return reflect.ValueOf(&t).Elem()
-}
-
-func Test_convertByKind(t *testing.T) {
+func Test_kindConverter(t *testing.T) {
 	t.Parallel()
 
+	assert.Nil(t, kindConverter(reflect.Struct))
+
 	tests := []struct {
 		name string // description of this test case
 		// Named input parameters for target function.
@@ -237,18 +235,13 @@ func Test_convertByKind(t *testing.T) {
 			data:    "not_a_bool",
 			field:   valueOfBool(),
 			wantErr: true,
-		}, {
-			name:    "convert time.Time default",
-			data:    "2021-01-01T00:00:00Z",
-			field:   valueOfTime(),
-			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			gotErr := convertByKind(tt.data, tt.field)
+			gotErr := kindConverter(tt.field.Kind())(tt.data, tt.field)
 			if tt.wantErr {
 				require.Error(t, gotErr)
 			} else {
@@ -290,3 +283,72 @@ func Test_getBitSize(t *testing.T) {
 		})
 	}
 }
+
+func Test_valuesConverterFor(t *testing.T) {
+	t.Parallel()
+
+	convertValues := func(values []string, field reflect.Value) error {
+		return valuesConverterFor(field.Type(), ParseTime)(values, field)
+	}
+
+	t.Run("slice_of_strings", func(t *testing.T) {
+		t.Parallel()
+
+		var tags []string
+		require.NoError(t, convertValues([]string{"a", "b"}, reflect.ValueOf(&tags).Elem()))
+		assert.Equal(t, []string{"a", "b"}, tags)
+	})
+	t.Run("slice_of_ints", func(t *testing.T) {
+		t.Parallel()
+
+		var ids []int
+		require.NoError(t, convertValues([]string{"1", "2", "3"}, reflect.ValueOf(&ids).Elem()))
+		assert.Equal(t, []int{1, 2, 3}, ids)
+	})
+	t.Run("slice_of_durations", func(t *testing.T) {
+		t.Parallel()
+
+		var ttl []time.Duration
+		require.NoError(t, convertValues([]string{"1s", "1h"}, reflect.ValueOf(&ttl).Elem()))
+		assert.Equal(t, []time.Duration{time.Second, time.Hour}, ttl)
+	})
+	t.Run("slice_of_times", func(t *testing.T) {
+		t.Parallel()
+
+		var dates []time.Time
+		require.NoError(t, convertValues([]string{"2021-01-01T00:00:00Z"}, reflect.ValueOf(&dates).Elem()))
+		assert.Equal(t, []time.Time{time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}, dates)
+	})
+	t.Run("slice_conversion_error", func(t *testing.T) {
+		t.Parallel()
+
+		var ids []int
+		require.Error(t, convertValues([]string{"1", "x"}, reflect.ValueOf(&ids).Elem()))
+		assert.Nil(t, ids)
+	})
+	t.Run("array", func(t *testing.T) {
+		t.Parallel()
+
+		var ids [3]uint8
+		require.NoError(t, convertValues([]string{"1", "2"}, reflect.ValueOf(&ids).Elem()))
+		assert.Equal(t, [3]uint8{1, 2, 0}, ids)
+	})
+	t.Run("array_too_many_values", func(t *testing.T) {
+		t.Parallel()
+
+		var ids [2]int
+
+		err := convertValues([]string{"1", "2", "3"}, reflect.ValueOf(&ids).Elem())
+
+		var tooMany TooManyValuesError
+		require.ErrorAs(t, err, &tooMany)
+		assert.Equal(t, TooManyValuesError{Max: 2, Count: 3}, tooMany)
+	})
+	t.Run("scalar_gets_first_value", func(t *testing.T) {
+		t.Parallel()
+
+		var id int
+		require.NoError(t, convertValues([]string{"1", "2"}, reflect.ValueOf(&id).Elem()))
+		assert.Equal(t, 1, id)
+	})
+}
@@ -0,0 +1,252 @@
+package typedhandler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+type (
+	// fieldBinding binds a struct field to a named request value (query, path, header or cookie)
+	fieldBinding struct {
+		source  string       // source of the value in the request (SourceQuery, SourcePath, ...)
+		name    string       // name of the value in the request
+		alias   string       // alternative name of the value in the request (e.g. "filter[name]" for "filter.name")
+		field   string       // name of the field in the struct, including the names of the parent structs
+		index   []int        // index path of the field in the struct, as used by reflect.Value.FieldByIndex
+		typ     reflect.Type // type of the field
+		multi   bool         // field is a slice or an array (or a pointer or Optional of one), and accepts multiple values
+		explode bool         // multiple values are sent as repeated keys (false: comma-separated)
+
+		convert       valueConverter  // converts the value of single-valued fields, resolved by SchemaHelper
+		convertValues valuesConverter // converts the values of multi-valued fields, resolved by SchemaHelper
+		set           fieldSetter     // sets the value of single-valued fields, precompiled by SchemaHelper
+
+		defaults   []string // values used when the value is absent from the request, from the "default" tag
+		hasDefault bool     // field has a "default" tag
+
+		httpCookie bool // field is a *http.Cookie, and receives the whole cookie
+		status     int  // status of the ParseErrors of the field, 0 for the default status of the source
+	}
+)
+
+// newFieldBinding creates a fieldBinding for the field from the tag value
+// The tag value is the name of the request value followed by comma-separated options:
+//
+//	Ids []int `query:"ids,explode=false"` // ?ids=1,2,3
+//
+// The prefix holds the names of the parent structs, when the field is nested in a tagged struct:
+//
+//	Filter struct {
+//		Name string `query:"name"`
+//	} `query:"filter"` // ?filter.name=john or ?filter[name]=john
+func newFieldBinding(source string, field *reflect.StructField, tagValue string, prefix []string) fieldBinding {
+	name, options, _ := strings.Cut(tagValue, ",")
+	binding := fieldBinding{
+		source:  source,
+		name:    name,
+		field:   field.Name,
+		index:   field.Index,
+		typ:     field.Type,
+		multi:   isMultiValued(field.Type),
+		explode: true,
+	}
+
+	if len(prefix) > 0 {
+		binding.name = strings.Join(prefix, ".") + "." + name
+		binding.alias = prefix[0] + "[" + strings.Join(append(slices.Clone(prefix[1:]), name), "][") + "]"
+	}
+
+	for option := range strings.SplitSeq(options, ",") {
+		if option == "explode=false" {
+			binding.explode = false
+		}
+	}
+
+	if value, ok := field.Tag.Lookup("default"); ok {
+		binding.defaults, binding.hasDefault = []string{value}, true
+		if binding.multi {
+			binding.defaults = splitValues(binding.defaults)
+		}
+	}
+
+	return binding
+}
+
+// checkDefault checks that the default value can be converted into the type of the field
+func (b *fieldBinding) checkDefault(fieldType reflect.Type) error {
+	if !b.hasDefault {
+		return nil
+	}
+
+	var (
+		value = reflect.New(fieldType).Elem()
+		err   error
+	)
+
+	if b.multi {
+		err = b.convertValues(b.defaults, value)
+	} else {
+		err = b.convert(firstValue(b.defaults), value)
+	}
+
+	if err != nil {
+		return fmt.Errorf("field %s: invalid default value %q: %w", b.field, strings.Join(b.defaults, ","), err)
+	}
+
+	return nil
+}
+
+// bind sets the values of the request into the bound field
+// Absent values (nil) are replaced by the default value, or leave the field unchanged when there is no default.
+// Present values, even empty, are always converted
+func (b *fieldBinding) bind(structValue reflect.Value, values []string) error {
+	if values == nil {
+		if !b.hasDefault {
+			return nil
+		}
+
+		values = b.defaults
+	}
+
+	if b.multi {
+		return b.bindValues(structValue, values)
+	}
+
+	return b.bindValue(structValue, firstValue(values))
+}
+
+// bindValue sets a single raw value into the bound field
+// Conversion errors are returned as a ParseError
+func (b *fieldBinding) bindValue(structValue reflect.Value, value string) error {
+	if b.multi {
+		if value == "" {
+			return b.bindValues(structValue, nil)
+		}
+
+		return b.bindValues(structValue, []string{value})
+	}
+
+	return b.parseError(value, b.set(structValue.Addr().UnsafePointer(), value))
+}
+
+// bindValues sets the raw values into the bound multi-valued field
+// Conversion errors are returned as a ParseError
+func (b *fieldBinding) bindValues(structValue reflect.Value, values []string) error {
+	if !b.explode {
+		values = splitValues(values)
+	}
+
+	field := fieldByIndex(structValue, b.index)
+	if err := b.convertValues(values, field); err != nil {
+		return b.parseError(strings.Join(values, ","), err)
+	}
+
+	return nil
+}
+
+// parseError wraps the conversion error of the value into a ParseError, or returns nil if err is nil
+func (b *fieldBinding) parseError(value string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return ParseError{
+		Source: b.source,
+		Param:  b.name,
+		Field:  b.field,
+		Type:   b.typ.String(),
+		Value:  value,
+		Err:    err,
+		status: b.status,
+	}
+}
+
+// lookup returns the values of the binding from the url values, trying the alias when the name is not found
+func (b *fieldBinding) lookup(values url.Values) []string {
+	found, ok := values[b.name]
+	if !ok && b.alias != "" {
+		found = values[b.alias]
+	}
+
+	return found
+}
+
+// dominantBindings removes the bindings hidden by others with the same name,
+// following the encoding/json rules for embedded structs: the shallower field wins.
+// Unlike encoding/json, fields with the same name at the same depth are reported as ambiguous
+func dominantBindings(bindings []fieldBinding) ([]fieldBinding, error) {
+	var (
+		err       error
+		dominants = make([]fieldBinding, 0, len(bindings))
+	)
+
+	for _, binding := range bindings {
+		ambiguous := false
+		hidden := slices.ContainsFunc(bindings, func(other fieldBinding) bool {
+			if other.name != binding.name || slices.Equal(other.index, binding.index) {
+				return false
+			}
+
+			ambiguous = ambiguous || len(other.index) == len(binding.index)
+
+			return len(other.index) < len(binding.index)
+		})
+
+		switch {
+		case hidden:
+		case ambiguous:
+			err = errors.Join(err,
+				fmt.Errorf("field %s is ambiguous: another field is bound to %q", binding.field, binding.name))
+		default:
+			dominants = append(dominants, binding)
+		}
+	}
+
+	return dominants, err
+}
+
+// fieldByIndex returns the nested field of the struct value by its index path,
+// allocating the nil embedded struct pointers along the way
+func fieldByIndex(structValue reflect.Value, index []int) reflect.Value {
+	if len(index) == 1 {
+		return structValue.Field(index[0])
+	}
+
+	value := structValue
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+
+			value = value.Elem()
+		}
+
+		value = value.Field(x)
+	}
+
+	return value
+}
+
+// firstValue returns the first of the values, or an empty string
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// splitValues splits each comma-separated value into multiple values
+func splitValues(values []string) []string {
+	split := make([]string, 0, len(values))
+	for _, value := range values {
+		split = append(split, strings.Split(value, ",")...)
+	}
+
+	return split
+}
@@ -0,0 +1,301 @@
+package typedhandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	Pagination struct {
+		Page  int `query:"page"`
+		Limit int `query:"limit"`
+	}
+	AuthHeaders struct {
+		Token string `header:"Authorization"`
+	}
+	Filter struct {
+		Name  string `query:"name"`
+		Range struct {
+			Min int `query:"min"`
+		} `query:"range"`
+	}
+	nestedRequest struct {
+		Pagination
+		*AuthHeaders
+		Filter Filter `query:"filter"`
+		Limit  int    `query:"limit"` // hides Pagination.Limit
+	}
+	ambiguousRequest struct {
+		Pagination
+		AlsoPagination
+	}
+	AlsoPagination struct {
+		Page int `query:"page"`
+	}
+)
+
+type multiValueRequest struct {
+	Tags    []string  `query:"tag"`
+	IDs     []int     `query:"ids,explode=false"`
+	Scores  [2]int    `query:"score"`
+	Accepts []string  `header:"Accept"`
+	Langs   [2]string `header:"X-Lang,explode=false"`
+	Path    []string  `path:"path,explode=false"`
+}
+
+func Test_newFieldBinding(t *testing.T) {
+	t.Parallel()
+
+	fields := reflect.TypeFor[multiValueRequest]()
+	t.Run("multi_value", func(t *testing.T) {
+		t.Parallel()
+
+		field := fields.Field(0)
+		binding := newFieldBinding(SourceQuery, &field, field.Tag.Get("query"), nil)
+		assert.Equal(t, fieldBinding{
+			source: SourceQuery, name: "tag", field: "Tags", index: []int{0}, typ: reflect.TypeFor[[]string](),
+			multi: true, explode: true,
+		}, binding)
+	})
+	t.Run("explode_false", func(t *testing.T) {
+		t.Parallel()
+
+		field := fields.Field(1)
+		binding := newFieldBinding(SourceQuery, &field, field.Tag.Get("query"), nil)
+		assert.Equal(t, fieldBinding{
+			source: SourceQuery, name: "ids", field: "IDs", index: []int{1}, typ: reflect.TypeFor[[]int](),
+			multi: true, explode: false,
+		}, binding)
+	})
+	t.Run("prefix", func(t *testing.T) {
+		t.Parallel()
+
+		field := fields.Field(0)
+		binding := newFieldBinding(SourceQuery, &field, field.Tag.Get("query"), []string{"filter", "labels"})
+		assert.Equal(t, "filter.labels.tag", binding.name)
+		assert.Equal(t, "filter[labels][tag]", binding.alias)
+	})
+}
+
+func TestSchemaHelper_multiValues(t *testing.T) {
+	t.Parallel()
+
+	sh := GetSchemaHelper[*multiValueRequest]()
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/?tag=a&tag=b&ids=1,2&ids=3&score=10&score=20", nil)
+		r.Header.Add("Accept", "text/plain")
+		r.Header.Add("Accept", "application/json")
+		r.Header.Set("X-Lang", "pt,es")
+		r.SetPathValue("path", "x,y")
+
+		instance := sh.GetInstance()
+		defer sh.PutInstance(instance)
+
+		structValue := reflect.ValueOf(instance).Elem()
+		require.NoError(t, sh.parseRequestQuery(r, structValue))
+		require.NoError(t, sh.parseRequestHeaders(r, structValue))
+		require.NoError(t, sh.parseRequestPath(r, structValue))
+
+		assert.Equal(t, []string{"a", "b"}, instance.Tags)
+		assert.Equal(t, []int{1, 2, 3}, instance.IDs)
+		assert.Equal(t, [2]int{10, 20}, instance.Scores)
+		assert.Equal(t, []string{"text/plain", "application/json"}, instance.Accepts)
+		assert.Equal(t, [2]string{"pt", "es"}, instance.Langs)
+		assert.Equal(t, []string{"x", "y"}, instance.Path)
+	})
+	t.Run("too_many_values", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/?score=1&score=2&score=3", nil)
+
+		instance := sh.GetInstance()
+		defer sh.PutInstance(instance)
+
+		err := sh.parseRequestQuery(r, reflect.ValueOf(instance).Elem())
+
+		var tooMany TooManyValuesError
+		require.ErrorAs(t, err, &tooMany)
+	})
+}
+
+type typedHeadersRequest struct {
+	Limit    *int            `header:"X-Limit"`
+	Since    *time.Time      `header:"If-Modified-Since"`
+	IDs      []int           `header:"X-Id"`
+	Timeouts []time.Duration `header:"X-Timeout,explode=false"`
+	RealIP   netip.Addr      `header:"X-Real-IP"`
+	Trace    Optional[bool]  `header:"X-Trace"`
+}
+
+func TestSchemaHelper_typedHeaders(t *testing.T) {
+	t.Parallel()
+
+	parser, release := CreateParser[*typedHeadersRequest]()
+	t.Run("present", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Limit", "10")
+		r.Header.Set("If-Modified-Since", "2025-12-31T10:00:00Z")
+		r.Header.Add("X-Id", "1")
+		r.Header.Add("X-Id", "2")
+		r.Header.Set("X-Timeout", "1s,500ms")
+		r.Header.Set("X-Real-IP", "10.0.0.1")
+		r.Header.Set("X-Trace", "true")
+
+		instance, err := parser(r)
+		defer release(instance)
+
+		require.NoError(t, err)
+		require.NotNil(t, instance.Limit)
+		assert.Equal(t, 10, *instance.Limit)
+		require.NotNil(t, instance.Since)
+		assert.Equal(t, time.Date(2025, 12, 31, 10, 0, 0, 0, time.UTC), *instance.Since)
+		assert.Equal(t, []int{1, 2}, instance.IDs)
+		assert.Equal(t, []time.Duration{time.Second, 500 * time.Millisecond}, instance.Timeouts)
+		assert.Equal(t, netip.MustParseAddr("10.0.0.1"), instance.RealIP)
+		assert.Equal(t, Some(true), instance.Trace)
+	})
+	t.Run("absent", func(t *testing.T) {
+		t.Parallel()
+
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/", nil))
+		defer release(instance)
+
+		require.NoError(t, err)
+		assert.Nil(t, instance.Limit)
+		assert.Nil(t, instance.Since)
+		assert.False(t, instance.Trace.IsSet())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Limit", "ten")
+
+		instance, err := parser(r)
+		defer release(instance)
+
+		var parseError ParseError
+		require.ErrorAs(t, err, &parseError)
+		assert.Equal(t, SourceHeader, parseError.Source)
+		assert.Equal(t, "X-Limit", parseError.Param)
+	})
+}
+
+func TestSchemaHelper_nestedFields(t *testing.T) {
+	t.Parallel()
+	t.Run("embedded_and_nested", func(t *testing.T) {
+		t.Parallel()
+
+		sh := GetSchemaHelper[*nestedRequest]()
+
+		r := httptest.NewRequest(http.MethodGet, "/?page=2&limit=50&filter.name=john&filter[range][min]=18", nil)
+		r.Header.Set("Authorization", "Bearer abc")
+
+		instance := sh.GetInstance()
+		defer sh.PutInstance(instance)
+
+		structValue := reflect.ValueOf(instance).Elem()
+		require.NoError(t, sh.parseRequestQuery(r, structValue))
+		require.NoError(t, sh.parseRequestHeaders(r, structValue))
+
+		assert.Equal(t, 2, instance.Page)
+		assert.Equal(t, 50, instance.Limit)
+		assert.Zero(t, instance.Pagination.Limit)
+		assert.Equal(t, "john", instance.Filter.Name)
+		assert.Equal(t, 18, instance.Filter.Range.Min)
+		require.NotNil(t, instance.AuthHeaders)
+		assert.Equal(t, "Bearer abc", instance.Token)
+	})
+	t.Run("ambiguous_fields_should_panic", func(t *testing.T) {
+		t.Parallel()
+		assert.Panics(t, func() {
+			_ = GetSchemaHelper[*ambiguousRequest]()
+		})
+	})
+}
+
+type (
+	defaultsRequest struct {
+		Page   int           `query:"page"      default:"1"`
+		Limit  uint8         `query:"limit"     default:"20"`
+		Sort   []string      `query:"sort"      default:"name,-created"`
+		Token  string        `header:"X-Token"  default:"anonymous"`
+		Region string        `path:"region"     default:"us"`
+		Theme  string        `cookie:"theme"    default:"dark"`
+		Wait   time.Duration `query:"wait"      default:"500ms"`
+		Offset int           `query:"offset"`
+	}
+	invalidDefaultRequest struct {
+		Page int `query:"page" default:"first"`
+	}
+)
+
+func TestSchemaHelper_defaults(t *testing.T) {
+	t.Parallel()
+
+	parser, release := CreateParser[*defaultsRequest]()
+
+	t.Run("absent", func(t *testing.T) {
+		t.Parallel()
+
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/", nil))
+		defer release(instance)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, instance.Page)
+		assert.Equal(t, uint8(20), instance.Limit)
+		assert.Equal(t, []string{"name", "-created"}, instance.Sort)
+		assert.Equal(t, "anonymous", instance.Token)
+		assert.Equal(t, "us", instance.Region)
+		assert.Equal(t, "dark", instance.Theme)
+		assert.Equal(t, 500*time.Millisecond, instance.Wait)
+		assert.Zero(t, instance.Offset)
+	})
+	t.Run("present", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/?page=3&limit=5&sort=id&wait=1s&offset=10", nil)
+		r.Header.Set("X-Token", "abc")
+		r.SetPathValue("region", "eu")
+		r.AddCookie(&http.Cookie{Name: "theme", Value: "light"})
+
+		instance, err := parser(r)
+		defer release(instance)
+
+		require.NoError(t, err)
+		assert.Equal(t, defaultsRequest{
+			Page: 3, Limit: 5, Sort: []string{"id"}, Token: "abc", Region: "eu", Theme: "light",
+			Wait: time.Second, Offset: 10,
+		}, *instance)
+	})
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		// present but empty values are converted, not replaced by the default
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/?page=&wait=1s", nil))
+		defer release(instance)
+
+		var parseError ParseError
+		require.ErrorAs(t, err, &parseError)
+		assert.Equal(t, "page", parseError.Param)
+	})
+	t.Run("invalid_default", func(t *testing.T) {
+		t.Parallel()
+
+		assert.PanicsWithError(t,
+			"github.com/guionardo/typedhandler/typedhandler.invalidDefaultRequest: "+
+				`field Page: invalid default value "first": strconv.ParseInt: parsing "first": invalid syntax`,
+			func() { GetSchemaHelper[*invalidDefaultRequest]() })
+	})
+}
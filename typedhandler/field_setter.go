@@ -0,0 +1,162 @@
+package typedhandler
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+	"unsafe"
+)
+
+// fieldSetter converts a raw value of the request and sets it into a field of the request struct,
+// given the base address of the struct
+type fieldSetter func(base unsafe.Pointer, raw string) error
+
+// newFieldSetter returns the setter of the field of the struct type t at the index path, precompiled for the
+// type of the field: strings, bools, numbers, time.Time and time.Duration are parsed and stored at the offset
+// of the field, with no reflection. Other types are converted by the converter into a reflect.Value of the field.
+// Fields nested in embedded struct pointers are reached by reflection, since their address depends on the pointer.
+// The time values are parsed by parseTime
+func newFieldSetter(t reflect.Type, index []int, convert valueConverter, parseTime timeParser) fieldSetter {
+	offset, direct := fieldOffset(t, index)
+	if !direct {
+		return func(base unsafe.Pointer, raw string) error {
+			return convert(raw, fieldByIndex(reflect.NewAt(t, base).Elem(), index))
+		}
+	}
+
+	fieldType := t.FieldByIndex(index).Type
+	if setter := typeSetter(fieldType, offset, parseTime); setter != nil {
+		return setter
+	}
+
+	return func(base unsafe.Pointer, raw string) error {
+		return convert(raw, reflect.NewAt(fieldType, unsafe.Add(base, offset)).Elem())
+	}
+}
+
+// fieldOffset returns the offset of the field at the index path from the start of the struct type t,
+// or false if the field is nested in an embedded struct pointer
+func fieldOffset(t reflect.Type, index []int) (offset uintptr, direct bool) {
+	for _, i := range index {
+		if t.Kind() != reflect.Struct {
+			return 0, false
+		}
+
+		field := t.Field(i)
+		offset += field.Offset
+		t = field.Type
+	}
+
+	return offset, true
+}
+
+// typeSetter returns the precompiled setter of fields of type t at the offset,
+// or nil if t has a registered converter, implements encoding.TextUnmarshaler or is not a basic type
+func typeSetter(t reflect.Type, offset uintptr, parseTime timeParser) fieldSetter {
+	switch {
+	case registeredConverter(t) != nil:
+		return nil
+	case t == timeType:
+		return func(base unsafe.Pointer, raw string) error {
+			value, err := parseTime(raw)
+			if err == nil {
+				*(*time.Time)(unsafe.Add(base, offset)) = value
+			}
+
+			return err
+		}
+	case t == durationType:
+		return func(base unsafe.Pointer, raw string) error {
+			value, err := time.ParseDuration(raw)
+			if err == nil {
+				*(*time.Duration)(unsafe.Add(base, offset)) = value
+			}
+
+			return err
+		}
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return nil
+	}
+
+	return kindSetter(t.Kind(), offset)
+}
+
+// kindSetter returns the precompiled setter of fields of the basic kind at the offset, or nil for other kinds
+// Named types of basic kinds share the memory layout of their kind, so they are set the same way
+func kindSetter(kind reflect.Kind, offset uintptr) fieldSetter {
+	switch kind {
+	case reflect.String:
+		return func(base unsafe.Pointer, raw string) error {
+			*(*string)(unsafe.Add(base, offset)) = raw
+			return nil
+		}
+	case reflect.Bool:
+		return func(base unsafe.Pointer, raw string) error {
+			value, err := strconv.ParseBool(raw)
+			if err == nil {
+				*(*bool)(unsafe.Add(base, offset)) = value
+			}
+
+			return err
+		}
+	case reflect.Int:
+		return intSetter[int](offset, strconv.IntSize)
+	case reflect.Int8:
+		return intSetter[int8](offset, bit8)
+	case reflect.Int16:
+		return intSetter[int16](offset, bit16)
+	case reflect.Int32:
+		return intSetter[int32](offset, bit32)
+	case reflect.Int64:
+		return intSetter[int64](offset, bit64)
+	case reflect.Uint:
+		return uintSetter[uint](offset, strconv.IntSize)
+	case reflect.Uint8:
+		return uintSetter[uint8](offset, bit8)
+	case reflect.Uint16:
+		return uintSetter[uint16](offset, bit16)
+	case reflect.Uint32:
+		return uintSetter[uint32](offset, bit32)
+	case reflect.Uint64:
+		return uintSetter[uint64](offset, bit64)
+	case reflect.Float32:
+		return floatSetter[float32](offset, bit32)
+	case reflect.Float64:
+		return floatSetter[float64](offset, bit64)
+	default:
+		return nil
+	}
+}
+
+func intSetter[T ~int | ~int8 | ~int16 | ~int32 | ~int64](offset uintptr, bitSize int) fieldSetter {
+	return func(base unsafe.Pointer, raw string) error {
+		value, err := strconv.ParseInt(raw, 10, bitSize)
+		if err == nil {
+			*(*T)(unsafe.Add(base, offset)) = T(value)
+		}
+
+		return err
+	}
+}
+
+func uintSetter[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](offset uintptr, bitSize int) fieldSetter {
+	return func(base unsafe.Pointer, raw string) error {
+		value, err := strconv.ParseUint(raw, 10, bitSize)
+		if err == nil {
+			*(*T)(unsafe.Add(base, offset)) = T(value)
+		}
+
+		return err
+	}
+}
+
+func floatSetter[T ~float32 | ~float64](offset uintptr, bitSize int) fieldSetter {
+	return func(base unsafe.Pointer, raw string) error {
+		value, err := strconv.ParseFloat(raw, bitSize)
+		if err == nil {
+			*(*T)(unsafe.Add(base, offset)) = T(value)
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,242 @@
+package typedhandler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	setterLevel    int
+	SetterEmbedded struct {
+		Token string
+	}
+	setterNested struct {
+		Level setterLevel
+	}
+	setterRequest struct {
+		*SetterEmbedded
+
+		S      string
+		B      bool
+		I      int
+		I8     int8
+		I16    int16
+		I32    int32
+		I64    int64
+		U      uint
+		U8     uint8
+		U16    uint16
+		U32    uint32
+		U64    uint64
+		F32    float32
+		F64    float64
+		T      time.Time
+		D      time.Duration
+		Color  converterColor
+		P      *int
+		Nested setterNested
+	}
+)
+
+func Test_newFieldSetter(t *testing.T) {
+	t.Parallel()
+
+	structType := reflect.TypeFor[setterRequest]()
+	tests := []struct {
+		field    string
+		raw      string
+		expected any
+	}{
+		{"Token", "abc", "abc"},
+		{"S", "text", "text"},
+		{"B", "true", true},
+		{"I", "-1", -1},
+		{"I8", "-8", int8(-8)},
+		{"I16", "-16", int16(-16)},
+		{"I32", "-32", int32(-32)},
+		{"I64", "-64", int64(-64)},
+		{"U", "1", uint(1)},
+		{"U8", "8", uint8(8)},
+		{"U16", "16", uint16(16)},
+		{"U32", "32", uint32(32)},
+		{"U64", "64", uint64(64)},
+		{"F32", "3.5", float32(3.5)},
+		{"F64", "2.25", 2.25},
+		{"T", "2025-01-02T03:04:05Z", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"D", "1m30s", 90 * time.Second},
+		{"Color", "blue", converterColor(3)},
+		{"P", "7", ptr(7)},
+		{"Level", "2", setterLevel(2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			t.Parallel()
+
+			field, found := structType.FieldByName(tt.field)
+			if tt.field == "Level" {
+				field, found = structType.FieldByName("Nested")
+				field.Index = append(field.Index, 0)
+			}
+
+			require.True(t, found)
+
+			var instance setterRequest
+
+			fieldType := structType.FieldByIndex(field.Index).Type
+			setter := newFieldSetter(structType, field.Index, elementConverter(fieldType, ParseTime), ParseTime)
+			require.NoError(t, setter(unsafe.Pointer(&instance), tt.raw))
+			assert.Equal(t, tt.expected, reflect.ValueOf(instance).FieldByIndex(field.Index).Interface())
+		})
+	}
+}
+
+func Test_newFieldSetter_errors(t *testing.T) {
+	t.Parallel()
+
+	structType := reflect.TypeFor[setterRequest]()
+
+	for _, name := range []string{"B", "I8", "U8", "F32", "T", "D", "Color", "P"} {
+		field, _ := structType.FieldByName(name)
+
+		var instance setterRequest
+
+		setter := newFieldSetter(structType, field.Index, elementConverter(field.Type, ParseTime), ParseTime)
+		require.Error(t, setter(unsafe.Pointer(&instance), "x"), name)
+		assert.Zero(t, instance, name)
+	}
+}
+
+func Test_fieldOffset(t *testing.T) {
+	t.Parallel()
+
+	structType := reflect.TypeFor[setterRequest]()
+
+	offset, direct := fieldOffset(structType, []int{0, 0}) // Token, in the embedded pointer
+	assert.False(t, direct)
+	assert.Zero(t, offset)
+
+	nested, _ := structType.FieldByName("Nested")
+	offset, direct = fieldOffset(structType, []int{nested.Index[0], 0})
+	assert.True(t, direct)
+	assert.Equal(t, nested.Offset, offset)
+}
+
+// benchmarkSetterRequest has 10 single-valued query fields
+type benchmarkSetterRequest struct {
+	Name    string        `query:"name"`
+	Page    int           `query:"page"`
+	Limit   uint16        `query:"limit"`
+	Active  bool          `query:"active"`
+	Score   float64       `query:"score"`
+	Since   time.Time     `query:"since"`
+	Timeout time.Duration `query:"timeout"`
+	Level   int8          `query:"level"`
+	Offset  int64         `query:"offset"`
+	Ratio   float32       `query:"ratio"`
+}
+
+// BenchmarkFieldBinding_setters compares the precompiled setters with the dispatch by the type and the kind
+// of the field on every value of the baseline (baselineConvertData), for a struct with 10 fields
+func BenchmarkFieldBinding_setters(b *testing.B) {
+	sh := GetSchemaHelper[*benchmarkSetterRequest]()
+	raw := map[string]string{
+		"name": "john", "page": "2", "limit": "50", "active": "true", "score": "9.5", "since": "2025-01-02T03:04:05Z",
+		"timeout": "1s", "level": "3", "offset": "100", "ratio": "0.5",
+	}
+
+	b.Run("baseline", func(b *testing.B) {
+		b.ReportAllocs()
+
+		instance := sh.GetInstance()
+		structValue := reflect.ValueOf(instance).Elem()
+
+		for b.Loop() {
+			for i := range sh.queryFields {
+				binding := &sh.queryFields[i]
+				if err := baselineConvertData(raw[binding.name], binding.index[0], structValue); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("setters", func(b *testing.B) {
+		b.ReportAllocs()
+
+		instance := sh.GetInstance()
+		structValue := reflect.ValueOf(instance).Elem()
+
+		for b.Loop() {
+			for i := range sh.queryFields {
+				binding := &sh.queryFields[i]
+				if err := binding.bindValue(structValue, raw[binding.name]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("parse_request_query", func(b *testing.B) {
+		b.ReportAllocs()
+
+		instance := sh.GetInstance()
+		structValue := reflect.ValueOf(instance).Elem()
+		r := httptest.NewRequest(http.MethodGet, "/?name=john&page=2&limit=50&active=true&score=9.5"+
+			"&since=2025-01-02T03:04:05Z&timeout=1s&level=3&offset=100&ratio=0.5", nil)
+
+		for b.Loop() {
+			if err := sh.parseRequestQuery(r, structValue); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// baselineConvertData is the conversion of the query values before the precompiled setters: the field is found
+// by its index, and the conversion is chosen by the type, then by the kind of the field, on every value
+func baselineConvertData(data string, fieldIndex int, structValue reflect.Value) (err error) {
+	fieldType := structValue.Field(fieldIndex).Type()
+	field := structValue.Field(fieldIndex)
+
+	switch fieldType {
+	case timeType:
+		var timeValue time.Time
+		if timeValue, err = ParseTime(data); err == nil {
+			field.Set(reflect.ValueOf(timeValue))
+		}
+
+		return err
+	case durationType:
+		return convertDuration(data, field)
+	}
+
+	return baselineConvertByKind(data, field)
+}
+
+// baselineConvertByKind is the conversion by the kind of the field of baselineConvertData
+func baselineConvertByKind(data string, field reflect.Value) error {
+	kind := field.Kind()
+
+	switch {
+	case kind == reflect.String:
+		field.SetString(data)
+		return nil
+	case kind == reflect.Bool:
+		return convertBool(data, field)
+	case isIntKind(kind):
+		return convertInt(data, getBitSize(kind), field)
+	case isUintKind(kind):
+		return convertUint(data, getBitSize(kind), field)
+	case isFloatKind(kind):
+		return convertFloat(data, getBitSize(kind), field)
+	default:
+		return fmt.Errorf("unsupported field type: %s", field.Type().Name())
+	}
+}
@@ -1,11 +1,11 @@
 package typedhandler
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
-	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -14,18 +14,26 @@ type (
 	HandlerFunc                                         func(w http.ResponseWriter, r *http.Request)
 	ServiceFunc[RIn RequestSchema, ROut ResponseSchema] func(ctx context.Context, request RIn) (response ROut, status int, err error) //nolint
 	ParseRequestFunc[RIn RequestSchema]                 func(r *http.Request) (instance RIn, err error)
+	ReleaseFunc[RIn RequestSchema]                      func(instance RIn)
+
+	// responseWriter writes responses of type ROut
+	responseWriter[ROut ResponseSchema] struct {
+		jsonEncode func(w io.Writer, response ROut) error // JSON fast path, if ROut implements easyjson.Marshaler
+		encoders   []registeredEncoder                    // encoders preferred over the registered encoders
+	}
 )
 
-// CreateHandler creates a typed HTTP handler with the provided request parser, done function, and service function.
+// CreateHandler creates a typed HTTP handler with the provided request parser, release function, and service function.
 // The request parser is responsible for parsing the incoming HTTP request into the specified request schema type RIn.
-// The done function is called at the end of the request handling, if provided.
+// The release function, if provided, is called with the parsed instance at the end of the request handling,
+// after the response is written, so the instance can be returned to the pool.
 // The service function processes the parsed request and returns a response of type ROut, along with an HTTP status code
 // and an error if any.
 //
 // Where it's used:
 //   - Examples: See `examples/simple/main.go` for a runnable example that calls
-//     requestParser, doneFunc := typedhandler.CreateParser[*LoginRequest]()
-//     handler := typedhandler.CreateHandler(requestParser, doneFunc, serviceFunc)
+//     requestParser, releaseFunc := typedhandler.CreateParser[*LoginRequest]()
+//     handler := typedhandler.CreateHandler(requestParser, releaseFunc, serviceFunc)
 //   - Quick start: README.md includes a short usage example that demonstrates
 //     creating a parser and passing it to `CreateHandler`.
 //   - Tests & Benchmarks: The behavior of `CreateHandler` is exercised in
@@ -35,80 +43,152 @@ type (
 //     delegates to `CreateHandler`, so you can use `CreateSimpleHandler` when
 //     you prefer the library to build the parser for you.
 //
+// The global middlewares added by Use wrap the service function. Use Chain to add the middlewares of a handler.
+//
+// The options (or a Config, with WithConfig) set the error renderer and the response encoders of the handler.
+// The settings of the parser are the options passed to CreateParser.
+//
 // In short, `CreateHandler` is the core function that composes parsing,
 // business logic (service function), and response/error writing into a
 // standard `http.HandlerFunc` usable with `http.HandleFunc` or any
 // net/http-compatible router.
 func CreateHandler[RIn RequestSchema, ROut ResponseSchema](
-	parseRequestFunc ParseRequestFunc[RIn], doneFunc func(),
-	serviceFunc ServiceFunc[RIn, ROut],
+	parseRequestFunc ParseRequestFunc[RIn], releaseFunc ReleaseFunc[RIn],
+	serviceFunc ServiceFunc[RIn, ROut], options ...Option,
+) HandlerFunc {
+	return newHandler(parseRequestFunc, releaseFunc, serviceFunc, NewConfig(options...), nil)
+}
+
+// newHandler creates the handler of the parser and the service func, with the settings of the config
+// The route set by WithRoute is recorded with the schema helper of the parser, or the helper of the config when nil
+func newHandler[RIn RequestSchema, ROut ResponseSchema](
+	parseRequestFunc ParseRequestFunc[RIn], releaseFunc ReleaseFunc[RIn],
+	serviceFunc ServiceFunc[RIn, ROut], config *Config, schemaHelper *SchemaHelper[RIn],
 ) HandlerFunc {
 	mustBeAPointer[RIn]()
 
-	var (
-		zero     RIn
-		preParse func(*http.Request) error
-	)
-	if preParseable, ok := any(zero).(PreParseable); ok {
-		preParse = preParseable.PreParse
+	if config.route != nil {
+		if schemaHelper == nil {
+			schemaHelper = getSchemaHelper[RIn](config)
+		}
+
+		recordRoute[RIn, ROut](config.route.registry, config.route.pattern, schemaHelper, config)
 	}
 
+	writeError := config.errorWriter()
+
+	responseWriter := newResponseWriter[ROut]()
+	responseWriter.encoders = config.responseEncoders
+	serviceFunc = withGlobalMiddlewares(serviceFunc)
+
 	return func(w http.ResponseWriter, r *http.Request) {
-		if doneFunc != nil {
-			defer doneFunc()
+		defer removeMultipartForm(r)
+
+		instance, err := parseRequestFunc(r)
+		if releaseFunc != nil {
+			// the instance is owned by this request until the response is written
+			defer releaseFunc(instance)
 		}
-		// pre-parse the request
-		if preParse != nil {
-			if err := preParse(r); err != nil {
-				writeErrorResponse(w, err)
-				return
-			}
+
+		if err == nil {
+			// the service is not called when no response could be accepted
+			err = checkAcceptable(r.Header.Get("Accept"), responseWriter.encoders)
 		}
-		instance, err := parseRequestFunc(r)
+
 		if err != nil {
-			writeErrorResponse(w, err)
+			writeError(w, r, err)
 			return
 		}
 
 		response, status, err := serviceFunc(r.Context(), instance)
 		if err == nil {
-			err = writeResponse(w, status, response)
+			err = responseWriter.write(w, r, status, response)
 		}
 
-		writeErrorResponse(w, err)
+		writeError(w, r, err)
 	}
 }
 
-func CreateSimpleHandler[RIn RequestSchema, ROut ResponseSchema](serviceFunc ServiceFunc[RIn, ROut]) HandlerFunc {
-	parserFunc, doneFunc := CreateParser[RIn]()
-	return CreateHandler(parserFunc, doneFunc, serviceFunc)
+// CreateSimpleHandler creates the parser of RIn with CreateParser and the handler with CreateHandler,
+// both with the options
+func CreateSimpleHandler[RIn RequestSchema, ROut ResponseSchema](serviceFunc ServiceFunc[RIn, ROut],
+	options ...Option,
+) HandlerFunc {
+	config := NewConfig(options...)
+	schemaHelper := getSchemaHelper[RIn](config)
+	parserFunc, releaseFunc := newParser(config, schemaHelper)
+
+	return newHandler(parserFunc, releaseFunc, serviceFunc, config, schemaHelper)
+}
+
+// newResponseWriter creates a responseWriter for ROut, detecting its JSON fast path
+func newResponseWriter[ROut ResponseSchema]() responseWriter[ROut] {
+	return responseWriter[ROut]{jsonEncode: jsonFastEncoder[ROut]()}
 }
 
-func writeResponse[ROut ResponseSchema](w http.ResponseWriter, status int, response ROut) error {
+// write encodes the response with the encoder negotiated from the Accept header of the request
+// The Content-Type header is always set to the content type of the chosen encoder.
+// When the negotiated encoder is the default JSONCodec, the JSON fast path is used, if any
+func (rw responseWriter[ROut]) write(w http.ResponseWriter, r *http.Request, status int, response ROut) error {
 	if status <= 0 {
 		status = http.StatusOK
 	}
 
-	responseBody, err := json.Marshal(response)
+	encoder, err := negotiateEncoder(r.Header.Get("Accept"), response, rw.encoders)
 	if err != nil {
 		return err
 	}
 
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	defer putBuffer(buffer)
+
+	if rw.jsonEncode != nil && encoder.encoder == ResponseEncoder(JSONCodec{}) {
+		err = rw.jsonEncode(buffer, response)
+	} else {
+		err = encoder.encoder.Encode(buffer, response)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", encoder.contentType)
 	w.WriteHeader(status)
-	_, err = w.Write(responseBody)
+	_, err = w.Write(buffer.Bytes())
 
 	return err
 }
 
-func writeErrorResponse(w http.ResponseWriter, err error) {
+// writeErrorResponse writes the error of the request with the package-level settings
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	(&Config{}).writeErrorResponse(w, r, err)
+}
+
+// writeErrorResponse writes the error of the request, as problem details if they are enabled
+// by the config or ProblemDetailsEnabled
+func (c *Config) writeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+
+	if c.problemDetailsEnabled() {
+		WriteProblemDetails(w, r, err)
+		return
+	}
+
 	var (
 		jsonError     HttpJsonError
 		httpError     HttpError
 		validateError validator.ValidationErrors
+		requestErrors RequestErrors
 	)
 	switch {
+	case errors.As(err, &requestErrors):
+		writeRequestErrors(w, r, requestErrors)
+	case errors.As(err, &validateError) && c.validationRenderer != nil:
+		c.validationRenderer(w, r, validateError)
 	case errors.As(err, &validateError):
-		validationErrorToHttpJsonError(err, w)
+		writeValidationErrors(w, r, validateError)
 	case errors.As(err, &jsonError):
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(jsonError.Status())
@@ -117,36 +197,7 @@ func writeErrorResponse(w http.ResponseWriter, err error) {
 		w.WriteHeader(httpError.Status())
 		_, _ = w.Write([]byte(httpError.Error()))
 	default:
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
-		}
-	}
-}
-
-func validationErrorToHttpJsonError(err error, w http.ResponseWriter) {
-	var validateError validator.ValidationErrors
-	if errors.As(err, &validateError) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-
-		sb := strings.Builder{}
-		sb.WriteString("[")
-
-		for i := range validateError {
-			before, after, found := strings.Cut(validateError[i].Error(), " Error:")
-			if found {
-				sb.WriteString("\"" + after + "\"")
-			} else {
-				sb.WriteString("\"" + before + "\"")
-			}
-
-			if i < len(validateError)-1 {
-				sb.WriteString(",")
-			}
-		}
-
-		sb.WriteString("]")
-		_, _ = w.Write([]byte(sb.String()))
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(err.Error()))
 	}
 }
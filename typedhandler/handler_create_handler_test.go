@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-
+	"runtime"
+	"sync"
 	"testing"
 
 	"github.com/guionardo/typedhandler/examples/sample"
@@ -67,6 +69,76 @@ func TestCreateHandler(t *testing.T) {
 	})
 }
 
+type (
+	concurrentRequest struct {
+		Name   string `json:"name"`
+		ID     string `path:"id"`
+		Page   string `query:"page"`
+		Tenant string `header:"X-Tenant"`
+	}
+	concurrentResponse struct {
+		Name   string `json:"name"`
+		ID     string `json:"id"`
+		Page   string `json:"page"`
+		Tenant string `json:"tenant"`
+	}
+)
+
+func TestCreateSimpleHandler_concurrentRequests(t *testing.T) {
+	t.Parallel()
+
+	const requests = 500
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /items/{id}", typedhandler.CreateSimpleHandler(
+		func(ctx context.Context, request *concurrentRequest) (concurrentResponse, int, error) {
+			// give other requests the chance to write into a shared instance
+			runtime.Gosched()
+
+			return concurrentResponse{
+				Name:   request.Name,
+				ID:     request.ID,
+				Page:   request.Page,
+				Tenant: request.Tenant,
+			}, http.StatusOK, nil
+		}))
+
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Go(func() {
+			expected := concurrentResponse{
+				ID:     fmt.Sprintf("id-%d", i),
+				Page:   fmt.Sprintf("page-%d", i),
+				Tenant: fmt.Sprintf("tenant-%d", i),
+			}
+			body := `{}`
+			// odd requests omit the body field, so stale values from reused instances would show up
+			if i%2 == 0 {
+				expected.Name = fmt.Sprintf("name-%d", i)
+				body = fmt.Sprintf(`{"name":%q}`, expected.Name)
+			}
+
+			request := httptest.NewRequest(http.MethodPost, "/items/"+expected.ID+"?page="+expected.Page,
+				bytes.NewBufferString(body))
+			request.Header.Set("X-Tenant", expected.Tenant)
+
+			response := httptest.NewRecorder()
+			mux.ServeHTTP(response, request)
+
+			if !assert.Equal(t, http.StatusOK, response.Code, response.Body.String()) {
+				return
+			}
+
+			var got concurrentResponse
+			if assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &got)) {
+				assert.Equal(t, expected, got)
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
 func BenchmarkCreateHandler(b *testing.B) {
 	b.ReportAllocs()
 	b.Run("easyjson_pool_enabled", easyjsonPoolEnabled)
@@ -76,11 +148,9 @@ func BenchmarkCreateHandler(b *testing.B) {
 }
 
 func easyjsonPoolEnabled(b *testing.B) {
-	typedhandler.PoolEnabled = true
-
-	parser, doneFunc := typedhandler.CreateParser[*sample.Request]()
+	parser, releaseFunc := typedhandler.CreateParser[*sample.Request](typedhandler.WithPool(true))
 	for b.Loop() {
-		handler := typedhandler.CreateHandler(parser, doneFunc, serviceRun)
+		handler := typedhandler.CreateHandler(parser, releaseFunc, serviceRun)
 		request, _ := http.NewRequest("POST", "/", bytes.NewBuffer([]byte(`{"name":"John Doe"}`)))
 		response := httptest.NewRecorder()
 		handler(response, request)
@@ -89,11 +159,9 @@ func easyjsonPoolEnabled(b *testing.B) {
 }
 
 func easyJsonPoolDisabled(b *testing.B) {
-	typedhandler.PoolEnabled = false
-
-	parser, doneFunc := typedhandler.CreateParser[*sample.Request]()
+	parser, releaseFunc := typedhandler.CreateParser[*sample.Request](typedhandler.WithPool(false))
 	for b.Loop() {
-		handler := typedhandler.CreateHandler(parser, doneFunc, serviceRun)
+		handler := typedhandler.CreateHandler(parser, releaseFunc, serviceRun)
 		request, _ := http.NewRequest("POST", "/", bytes.NewBuffer([]byte(`{"name":"John Doe"}`)))
 		response := httptest.NewRecorder()
 		handler(response, request)
@@ -102,11 +170,9 @@ func easyJsonPoolDisabled(b *testing.B) {
 }
 
 func normalJsonPoolEnabled(b *testing.B) {
-	typedhandler.PoolEnabled = true
-
-	parser, doneFunc := typedhandler.CreateParser[*sample.RequestNormal]()
+	parser, releaseFunc := typedhandler.CreateParser[*sample.RequestNormal](typedhandler.WithPool(true))
 	for b.Loop() {
-		handler := typedhandler.CreateHandler(parser, doneFunc, serviceRunNormal)
+		handler := typedhandler.CreateHandler(parser, releaseFunc, serviceRunNormal)
 		request, _ := http.NewRequest("POST", "/", bytes.NewBuffer([]byte(`{"name":"John Doe"}`)))
 		response := httptest.NewRecorder()
 		handler(response, request)
@@ -115,14 +181,78 @@ func normalJsonPoolEnabled(b *testing.B) {
 }
 
 func normalJsonPoolDisabled(b *testing.B) {
-	typedhandler.PoolEnabled = false
-
-	parser, doneFunc := typedhandler.CreateParser[*sample.RequestNormal]()
+	parser, releaseFunc := typedhandler.CreateParser[*sample.RequestNormal](typedhandler.WithPool(false))
 	for b.Loop() {
-		handler := typedhandler.CreateHandler(parser, doneFunc, serviceRunNormal)
+		handler := typedhandler.CreateHandler(parser, releaseFunc, serviceRunNormal)
 		request, _ := http.NewRequest("POST", "/", bytes.NewBuffer([]byte(`{"name":"John Doe"}`)))
 		response := httptest.NewRecorder()
 		handler(response, request)
 	}
 
 }
+
+// plainRequest has the fields and tags of sample.Request, without its generated easyjson methods
+type plainRequest sample.Request
+
+const fullRequestBody = `{"name":"John Doe","age":30,"city":"Curitiba","state":"PR","country":"BR",` +
+	`"zip":"80000-000","phone":"+55 41 99999-9999","email":"john@doe.com","password":"secret"}`
+
+// BenchmarkCreateParser_jsonBody compares the easyjson fast path (sample.Request)
+// with encoding/json (plainRequest, same fields without generated code).
+// The fast path allocates fewer bytes, but as many times: both allocate the decoded strings
+func BenchmarkCreateParser_jsonBody(b *testing.B) {
+	b.Run("easyjson", func(b *testing.B) {
+		benchmarkParser[*sample.Request](b)
+	})
+	b.Run("encoding_json", func(b *testing.B) {
+		benchmarkParser[*plainRequest](b)
+	})
+}
+
+func benchmarkParser[RIn typedhandler.RequestSchema](b *testing.B) {
+	b.Helper()
+	b.ReportAllocs()
+
+	parser, releaseFunc := typedhandler.CreateParser[RIn]()
+	body := bytes.NewReader([]byte(fullRequestBody))
+	request := httptest.NewRequest(http.MethodPost, "/", body)
+
+	for b.Loop() {
+		body.Reset([]byte(fullRequestBody))
+
+		instance, err := parser(request)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		releaseFunc(instance)
+	}
+}
+
+// BenchmarkCreateHandler_jsonResponse compares the easyjson fast path (sample.Request)
+// with encoding/json (plainRequest) to encode responses
+func BenchmarkCreateHandler_jsonResponse(b *testing.B) {
+	b.Run("easyjson", func(b *testing.B) {
+		benchmarkResponse(b, sample.Request{Name: "John Doe", Email: "john@doe.com"})
+	})
+	b.Run("encoding_json", func(b *testing.B) {
+		benchmarkResponse(b, plainRequest{Name: "John Doe", Email: "john@doe.com"})
+	})
+}
+
+func benchmarkResponse[ROut typedhandler.ResponseSchema](b *testing.B, response ROut) {
+	b.Helper()
+	b.ReportAllocs()
+
+	handler := typedhandler.CreateHandler(
+		func(r *http.Request) (*sample.Request, error) { return nil, nil },
+		nil,
+		func(ctx context.Context, request *sample.Request) (ROut, int, error) {
+			return response, http.StatusOK, nil
+		})
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	for b.Loop() {
+		handler(httptest.NewRecorder(), request)
+	}
+}
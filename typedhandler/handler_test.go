@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-playground/validator/v10"
@@ -42,14 +43,14 @@ func Test_writeErrorResponse(t *testing.T) {
 		t.Parallel()
 
 		w := httptest.NewRecorder()
-		writeErrorResponse(w, nil)
+		writeErrorResponse(w, nil, nil)
 		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
 	})
 	t.Run("error", func(t *testing.T) {
 		t.Parallel()
 
 		w := httptest.NewRecorder()
-		writeErrorResponse(w, errors.New("Bad Request"))
+		writeErrorResponse(w, nil, errors.New("Bad Request"))
 		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
 		assert.Equal(t, "Bad Request", w.Body.String())
 	})
@@ -57,7 +58,7 @@ func Test_writeErrorResponse(t *testing.T) {
 		t.Parallel()
 
 		w := httptest.NewRecorder()
-		writeErrorResponse(w, httpError{StatusCode: http.StatusBadRequest, Message: "Bad Request"})
+		writeErrorResponse(w, nil, httpError{StatusCode: http.StatusBadRequest, Message: "Bad Request"})
 		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 		assert.Equal(t, "Bad Request", w.Body.String())
 	})
@@ -66,7 +67,7 @@ func Test_writeErrorResponse(t *testing.T) {
 
 		w := httptest.NewRecorder()
 		writeErrorResponse(
-			w,
+			w, nil,
 			jsonError{httpError: httpError{StatusCode: http.StatusBadRequest, Message: "Bad Request"}},
 		)
 		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
@@ -85,32 +86,41 @@ func Test_writeErrorResponse(t *testing.T) {
 
 		w := httptest.NewRecorder()
 
-		writeErrorResponse(w, validationErrs)
+		writeErrorResponse(w, nil, validationErrs)
 		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
-		assert.JSONEq(
-			t,
-			`["Field validation for 'Name' failed on the 'required' tag",`+
-				`"Field validation for 'Age' failed on the 'min' tag"]`,
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"errors":[`+
+			`{"field":"Name","rule":"required","value":"",`+
+			`"message":"Field validation for 'Name' failed on the 'required' tag"},`+
+			`{"field":"Age","rule":"min","param":"1","value":0,`+
+			`"message":"Field validation for 'Age' failed on the 'min' tag"}]}`,
 			w.Body.String(),
 		)
 	})
 }
 
-func Test_writeResponse(t *testing.T) {
+func Test_responseWriter_write(t *testing.T) {
 	t.Parallel()
+
+	writer := newResponseWriter[httpError]()
+	ok := httpError{StatusCode: http.StatusOK, Message: "OK"}
+
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 
 		w := httptest.NewRecorder()
-		require.NoError(t, writeResponse(w, int(http.StatusOK), httpError{StatusCode: http.StatusOK, Message: "OK"}))
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		require.NoError(t, writer.write(w, r, int(http.StatusOK), ok))
 		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
 		assert.JSONEq(t, `{"StatusCode":200,"Message":"OK"}`, w.Body.String())
 	})
 	t.Run("default_status", func(t *testing.T) {
 		t.Parallel()
 
 		w := httptest.NewRecorder()
-		require.NoError(t, writeResponse(w, 0, httpError{StatusCode: http.StatusOK, Message: "OK"}))
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		require.NoError(t, writer.write(w, r, 0, ok))
 		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
 		assert.JSONEq(t, `{"StatusCode":200,"Message":"OK"}`, w.Body.String())
 	})
@@ -123,10 +133,45 @@ func Test_writeResponse(t *testing.T) {
 			C chan int
 		}
 
-		err := writeResponse(w, int(http.StatusOK), chanType{C: make(chan int)})
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		err := newResponseWriter[chanType]().write(w, r, int(http.StatusOK), chanType{C: make(chan int)})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "json: unsupported type: chan int")
 	})
+	t.Run("accept_xml", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/html;q=0.9, application/xml")
+		require.NoError(t, writer.write(w, r, http.StatusOK, ok))
+		assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "<httpError><StatusCode>200</StatusCode><Message>OK</Message></httpError>", w.Body.String())
+	})
+	t.Run("accept_text", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/*")
+		require.NoError(t, newResponseWriter[string]().write(w, r, http.StatusCreated, "created"))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "created", w.Body.String())
+	})
+	t.Run("not_acceptable", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/plain, application/json;q=0")
+
+		err := writer.write(w, r, http.StatusOK, ok)
+
+		var notAcceptable NotAcceptableError
+		require.ErrorAs(t, err, &notAcceptable)
+		assert.Equal(t, http.StatusNotAcceptable, notAcceptable.Status())
+	})
 }
 
 func TestCreateHandler(t *testing.T) {
@@ -159,4 +204,99 @@ func TestCreateHandler(t *testing.T) {
 		handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
 		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
 	})
+	t.Run("not_acceptable_should_not_call_the_service", func(t *testing.T) {
+		t.Parallel()
+
+		called := false
+		handler := CreateSimpleHandler(func(context.Context, *hooksRequest) (string, int, error) {
+			called = true
+			return "", http.StatusOK, nil
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "image/png")
+		handler(w, r)
+
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.False(t, called)
+	})
+}
+
+// hooksRequest sets its tenant from the host before parsing, and normalizes its email after parsing
+type hooksRequest struct {
+	Tenant string `query:"tenant"`
+	Email  string `query:"email"  validate:"omitempty,email"`
+	Domain string
+}
+
+func (r *hooksRequest) PreParse(req *http.Request) error {
+	if req.Host == "blocked.example.com" {
+		return httpError{StatusCode: http.StatusForbidden, Message: "blocked"}
+	}
+
+	r.Tenant = req.Host // a default, replaced by the tenant query value
+
+	return nil
+}
+
+func (r *hooksRequest) PostParse(*http.Request) error {
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
+	if r.Email == "" {
+		return nil
+	}
+
+	_, domain, found := strings.Cut(r.Email, "@")
+	if !found {
+		return httpError{StatusCode: http.StatusUnprocessableEntity, Message: "email without domain"}
+	}
+
+	r.Domain = domain
+
+	return nil
+}
+
+func TestCreateHandler_parseHooks(t *testing.T) {
+	t.Parallel()
+
+	handler := CreateSimpleHandler(func(_ context.Context, request *hooksRequest) (*hooksRequest, int, error) {
+		return request, http.StatusOK, nil
+	})
+
+	tests := []struct {
+		name   string
+		host   string
+		query  string
+		status int
+		body   string
+	}{
+		{"pre_parse", "api.example.com", "", http.StatusOK,
+			`{"Tenant":"api.example.com","Email":"","Domain":""}`},
+		{"bound_after_pre_parse", "api.example.com", "tenant=acme", http.StatusOK,
+			`{"Tenant":"acme","Email":"","Domain":""}`},
+		{"post_parse", "api.example.com", "email=%20John@Example.COM%20", http.StatusOK,
+			`{"Tenant":"api.example.com","Email":"john@example.com","Domain":"example.com"}`},
+		{"pre_parse_error", "blocked.example.com", "", http.StatusForbidden, "blocked"},
+		{"post_parse_error", "api.example.com", "email=john", http.StatusUnprocessableEntity,
+			"email without domain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			r.Host = tt.host
+
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.JSONEq(t, tt.body, w.Body.String())
+			} else {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
 }
@@ -1,6 +1,9 @@
 package typedhandler
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 type (
 	// Resettable represents a struct that can reset its fields to default values
@@ -8,14 +11,48 @@ type (
 		Reset()
 	}
 
+	// StructValidator validates the request schemas, like *validator.Validate.
+	// Other validation libraries are plugged in, with WithValidator, by an adapter that implements it.
+	// Errors that are not validator.ValidationErrors are written like the service errors
+	StructValidator interface {
+		// Struct validates the fields of the struct
+		Struct(s any) error
+		// StructExcept validates the fields of the struct, except the named fields
+		StructExcept(s any, fields ...string) error
+	}
+
+	// Validatable represents a struct with its own validation, that runs after the validation of the
+	// "validate" tags. Return an HttpError to set the status of the response
 	Validatable interface {
 		Validate() error
 	}
 
+	// ContextValidatable represents a struct validated with the context of the request, e.g. to check
+	// the values against a repository. It runs after the validation of the tags and Validatable
+	ContextValidatable interface {
+		ValidateContext(ctx context.Context) error
+	}
+
+	// MultipartMemoryLimiter represents a struct that sets the max memory used to parse multipart forms
+	// The remaining of the form is stored in temporary files
+	MultipartMemoryLimiter interface {
+		MaxMultipartMemory() int64
+	}
+
+	// PreParseable represents a struct prepared before the request values are bound to its fields,
+	// e.g. to set values that depend on the request. It is called on the instance of the request.
+	// Return an HttpError to set the status of the response
 	PreParseable interface {
 		PreParse(r *http.Request) error
 	}
 
+	// PostParseable represents a struct normalized after the request values are bound to its fields and
+	// before it is validated, e.g. to trim values, lowercase emails or derive fields.
+	// Return an HttpError to set the status of the response
+	PostParseable interface {
+		PostParse(r *http.Request) error
+	}
+
 	HttpError interface {
 		error
 		Status() int
@@ -0,0 +1,111 @@
+package typedhandler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+type (
+	// Middleware wraps a ServiceFunc with cross-cutting logic that sees the typed request and response.
+	// A middleware can inspect or modify the parsed request before calling next, return its own response
+	// or error without calling next, and post-process the response and the status returned by next:
+	//
+	//	func authorize(next ServiceFunc[*UpdateUserRequest, *User]) ServiceFunc[*UpdateUserRequest, *User] {
+	//		return func(ctx context.Context, request *UpdateUserRequest) (*User, int, error) {
+	//			if !canUpdate(ctx, request.ID) {
+	//				return nil, http.StatusForbidden, ErrForbidden
+	//			}
+	//
+	//			return next(ctx, request)
+	//		}
+	//	}
+	Middleware[RIn RequestSchema, ROut ResponseSchema] func(next ServiceFunc[RIn, ROut]) ServiceFunc[RIn, ROut]
+
+	// MiddlewareTypeError is returned when a global middleware replaces the request or the response
+	// with a value that is not of the types of the handler
+	MiddlewareTypeError struct {
+		Expected string // type of the handler
+		Got      string // type of the value returned by the middleware
+	}
+)
+
+var (
+	globalMiddlewares     []Middleware[any, any]
+	globalMiddlewaresLock sync.RWMutex
+)
+
+func (e MiddlewareTypeError) Error() string {
+	return fmt.Sprintf("middleware returned a value of type %s, expected %s", e.Got, e.Expected)
+}
+
+// Chain wraps the service func with the middlewares of a handler
+// The first middleware is the outermost: it runs first on the request, and last on the response
+//
+//	handler := typedhandler.CreateHandler(parser, release, typedhandler.Chain(updateUser, audit, authorize))
+func Chain[RIn RequestSchema, ROut ResponseSchema](
+	serviceFunc ServiceFunc[RIn, ROut], middlewares ...Middleware[RIn, ROut],
+) ServiceFunc[RIn, ROut] {
+	for _, middleware := range slices.Backward(middlewares) {
+		serviceFunc = middleware(serviceFunc)
+	}
+
+	return serviceFunc
+}
+
+// Use adds global middlewares, applied by CreateHandler to the handlers created afterwards.
+// Global middlewares run around the service func and the middlewares of each handler, in the order they were added.
+// They see the request and the response as any, so they use type assertions or interfaces implemented by the
+// request types.
+// Each handler captures the global middlewares when it is created: the middlewares added later don't apply to
+// the handlers already created, and the added middlewares can't be removed. It should be called before the
+// handlers are created
+func Use(middlewares ...Middleware[any, any]) {
+	globalMiddlewaresLock.Lock()
+	defer globalMiddlewaresLock.Unlock()
+
+	globalMiddlewares = append(globalMiddlewares, middlewares...)
+}
+
+// resetMiddlewares replaces the global middlewares, for the tests
+func resetMiddlewares(middlewares ...Middleware[any, any]) {
+	globalMiddlewaresLock.Lock()
+	defer globalMiddlewaresLock.Unlock()
+
+	globalMiddlewares = middlewares
+}
+
+// withGlobalMiddlewares wraps the service func with the global middlewares added by Use
+func withGlobalMiddlewares[RIn RequestSchema, ROut ResponseSchema](
+	serviceFunc ServiceFunc[RIn, ROut],
+) ServiceFunc[RIn, ROut] {
+	globalMiddlewaresLock.RLock()
+	middlewares := slices.Clone(globalMiddlewares)
+	globalMiddlewaresLock.RUnlock()
+
+	if len(middlewares) == 0 {
+		return serviceFunc
+	}
+
+	next := Chain(func(ctx context.Context, request any) (any, int, error) {
+		typed, ok := request.(RIn)
+		if !ok {
+			return nil, 0, MiddlewareTypeError{Expected: reflect.TypeFor[RIn]().String(), Got: fmt.Sprintf("%T", request)}
+		}
+
+		return serviceFunc(ctx, typed)
+	}, middlewares...)
+
+	return func(ctx context.Context, request RIn) (ROut, int, error) {
+		response, status, err := next(ctx, request)
+
+		typed, ok := response.(ROut)
+		if !ok && response != nil && err == nil {
+			err = MiddlewareTypeError{Expected: reflect.TypeFor[ROut]().String(), Got: fmt.Sprintf("%T", response)}
+		}
+
+		return typed, status, err
+	}
+}
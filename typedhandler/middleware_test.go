@@ -0,0 +1,171 @@
+package typedhandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	middlewareRequest struct {
+		User string `header:"X-User"`
+		Name string `query:"name"`
+	}
+	middlewareResponse struct {
+		Greeting string   `json:"greeting"`
+		Trace    []string `json:"trace"`
+	}
+)
+
+func greetService(_ context.Context, request *middlewareRequest) (*middlewareResponse, int, error) {
+	return &middlewareResponse{Greeting: "hello " + request.Name, Trace: []string{"service"}}, http.StatusOK, nil
+}
+
+// traceMiddleware records its name in the response trace, around the next service func
+func traceMiddleware(name string) Middleware[*middlewareRequest, *middlewareResponse] {
+	return func(next ServiceFunc[*middlewareRequest, *middlewareResponse],
+	) ServiceFunc[*middlewareRequest, *middlewareResponse] {
+		return func(ctx context.Context, request *middlewareRequest) (*middlewareResponse, int, error) {
+			response, status, err := next(ctx, request)
+			if response != nil {
+				response.Trace = append(response.Trace, name)
+			}
+
+			return response, status, err
+		}
+	}
+}
+
+func authorizeMiddleware(next ServiceFunc[*middlewareRequest, *middlewareResponse],
+) ServiceFunc[*middlewareRequest, *middlewareResponse] {
+	return func(ctx context.Context, request *middlewareRequest) (*middlewareResponse, int, error) {
+		if request.User == "" {
+			return nil, 0, httpError{StatusCode: http.StatusUnauthorized, Message: "unauthorized"}
+		}
+
+		request.Name = strings.ToUpper(request.Name) // modifies the parsed request
+
+		response, _, err := next(ctx, request)
+
+		return response, http.StatusAccepted, err // post-processes the status
+	}
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	parser, release := CreateParser[*middlewareRequest]()
+	handler := CreateHandler(parser, release, Chain(greetService,
+		traceMiddleware("outer"),
+		authorizeMiddleware,
+		traceMiddleware("inner"),
+	))
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/?name=john", nil)
+		r.Header.Set("X-User", "admin")
+
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.JSONEq(t, `{"greeting":"hello JOHN","trace":["service","inner","outer"]}`, w.Body.String())
+	})
+	t.Run("short_circuit", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/?name=john", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "unauthorized", w.Body.String())
+	})
+	t.Run("no_middlewares", func(t *testing.T) {
+		t.Parallel()
+
+		response, _, err := Chain(greetService)(t.Context(), &middlewareRequest{Name: "x"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"service"}, response.Trace)
+	})
+}
+
+func TestUse(t *testing.T) { //nolint:paralleltest // changes the global middlewares
+	t.Cleanup(func() { resetMiddlewares() })
+
+	var audited []string
+
+	Use(func(next ServiceFunc[any, any]) ServiceFunc[any, any] {
+		return func(ctx context.Context, request any) (any, int, error) {
+			if request, ok := request.(*middlewareRequest); ok {
+				audited = append(audited, request.User)
+			}
+
+			return next(ctx, request)
+		}
+	}, func(next ServiceFunc[any, any]) ServiceFunc[any, any] {
+		return func(ctx context.Context, request any) (any, int, error) {
+			response, status, err := next(ctx, request)
+			if response, ok := response.(*middlewareResponse); ok {
+				response.Trace = append(response.Trace, "global")
+			}
+
+			return response, status, err
+		}
+	})
+
+	parser, release := CreateParser[*middlewareRequest]()
+	handler := CreateHandler(parser, release, Chain(greetService, traceMiddleware("handler")))
+
+	r := httptest.NewRequest(http.MethodGet, "/?name=john", nil)
+	r.Header.Set("X-User", "admin")
+
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"greeting":"hello john","trace":["service","handler","global"]}`, w.Body.String())
+	assert.Equal(t, []string{"admin"}, audited)
+
+	t.Run("captured_at_creation", func(t *testing.T) { //nolint:paralleltest // changes the global middlewares
+		Use(func(ServiceFunc[any, any]) ServiceFunc[any, any] {
+			return func(context.Context, any) (any, int, error) {
+				return nil, http.StatusTeapot, nil
+			}
+		})
+
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/?name=john", nil))
+		assert.JSONEq(t, `{"greeting":"hello john","trace":["service","handler","global"]}`, w.Body.String(),
+			"the middlewares added after the handler was created don't apply to it")
+	})
+	t.Run("type_errors", func(t *testing.T) { //nolint:paralleltest // changes the global middlewares
+		resetMiddlewares(func(ServiceFunc[any, any]) ServiceFunc[any, any] {
+			return func(context.Context, any) (any, int, error) {
+				return "not a response", http.StatusOK, nil
+			}
+		})
+
+		_, _, err := withGlobalMiddlewares(greetService)(t.Context(), &middlewareRequest{})
+		require.EqualError(t, err,
+			"middleware returned a value of type string, expected *typedhandler.middlewareResponse")
+
+		resetMiddlewares(func(next ServiceFunc[any, any]) ServiceFunc[any, any] {
+			return func(ctx context.Context, _ any) (any, int, error) {
+				return next(ctx, "not a request")
+			}
+		})
+
+		_, _, err = withGlobalMiddlewares(greetService)(t.Context(), &middlewareRequest{})
+
+		var typeError MiddlewareTypeError
+		require.ErrorAs(t, err, &typeError)
+		assert.Equal(t, "*typedhandler.middlewareRequest", typeError.Expected)
+	})
+}
@@ -0,0 +1,82 @@
+package typedhandler
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// defaultMaxMemory is the max memory used to parse multipart forms, the same used by http.Request.FormFile
+const defaultMaxMemory = 32 << 20 // 32 MB
+
+var (
+	fileHeaderType  = reflect.TypeFor[*multipart.FileHeader]()
+	fileHeadersType = reflect.TypeFor[[]*multipart.FileHeader]()
+)
+
+// checkFile identifies multipart file fields from struct tags "file"
+// A file field must be a *multipart.FileHeader or a []*multipart.FileHeader
+func (sh *SchemaHelper[RIn]) checkFile(field *reflect.StructField) *SchemaHelper[RIn] {
+	fileField := field.Tag.Get("file")
+	if fileField == "" {
+		return sh
+	}
+
+	if field.Type != fileHeaderType && field.Type != fileHeadersType {
+		sh.errors = errors.Join(sh.errors,
+			fmt.Errorf("file field %s must be a %s or a %s", field.Name, fileHeaderType, fileHeadersType))
+		return sh
+	}
+
+	sh.fileFields = append(sh.fileFields, newFieldBinding(SourceForm, field, fileField, nil))
+	if sh.bodyType == NoBody || sh.bodyType == FormBody {
+		sh.bodyType = MultipartBody
+	}
+
+	return sh
+}
+
+// checkMultipartMemory sets the max memory for multipart forms from the MultipartMemoryLimiter interface
+func (sh *SchemaHelper[RIn]) checkMultipartMemory(instance any) {
+	sh.maxMemory = defaultMaxMemory
+	if limiter, ok := instance.(MultipartMemoryLimiter); ok {
+		sh.maxMemory = limiter.MaxMultipartMemory()
+	}
+}
+
+// parseMultipartForm parses the multipart form, sets the file fields and returns the form values
+// The form is kept in the request, the handler removes its temporary files when the response is written
+func (sh *SchemaHelper[RIn]) parseMultipartForm(r *http.Request, structValue reflect.Value) (
+	url.Values, error,
+) {
+	if err := r.ParseMultipartForm(sh.maxMemory); err != nil {
+		return nil, err
+	}
+
+	for i := range sh.fileFields {
+		binding := &sh.fileFields[i]
+
+		files := r.MultipartForm.File[binding.name]
+		if len(files) == 0 {
+			continue
+		}
+
+		if binding.multi {
+			fieldByIndex(structValue, binding.index).Set(reflect.ValueOf(files))
+		} else {
+			fieldByIndex(structValue, binding.index).Set(reflect.ValueOf(files[0]))
+		}
+	}
+
+	return r.MultipartForm.Value, nil
+}
+
+// removeMultipartForm removes the temporary files of the multipart form parsed for the request
+func removeMultipartForm(r *http.Request) {
+	if r.MultipartForm != nil {
+		_ = r.MultipartForm.RemoveAll()
+	}
+}
@@ -0,0 +1,132 @@
+package typedhandler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	uploadRequest struct {
+		Title       string                  `form:"title"`
+		Avatar      *multipart.FileHeader   `file:"avatar"`
+		Attachments []*multipart.FileHeader `file:"attachment"`
+	}
+	smallUploadRequest struct {
+		Avatar *multipart.FileHeader `file:"avatar"`
+	}
+	invalidUploadRequest struct {
+		Avatar []byte `file:"avatar"`
+	}
+)
+
+func (r *smallUploadRequest) MaxMultipartMemory() int64 {
+	return 1
+}
+
+func newMultipartRequest(t *testing.T, values map[string]string, files map[string][]string) *http.Request {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for key, value := range values {
+		require.NoError(t, writer.WriteField(key, value))
+	}
+
+	for key, contents := range files {
+		for _, content := range contents {
+			part, err := writer.CreateFormFile(key, key+".txt")
+			require.NoError(t, err)
+
+			_, err = part.Write([]byte(content))
+			require.NoError(t, err)
+		}
+	}
+
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return req
+}
+
+func TestSchemaHelper_parseMultipartForm(t *testing.T) {
+	t.Parallel()
+	t.Run("values_and_files", func(t *testing.T) {
+		t.Parallel()
+
+		sh := GetSchemaHelper[*uploadRequest]()
+		assert.Equal(t, MultipartBody, sh.bodyType)
+		assert.Equal(t, int64(defaultMaxMemory), sh.maxMemory)
+
+		req := newMultipartRequest(t,
+			map[string]string{"title": "holidays"},
+			map[string][]string{"avatar": {"me"}, "attachment": {"one", "two"}})
+
+		instance := sh.GetInstance()
+		defer sh.PutInstance(instance)
+
+		require.NoError(t, sh.parseRequestBody(req, instance))
+		require.NoError(t, sh.parseRequestForm(req, reflect.ValueOf(instance).Elem()))
+		assert.Equal(t, "holidays", instance.Title)
+		require.NotNil(t, instance.Avatar)
+		assert.Equal(t, "avatar.txt", instance.Avatar.Filename)
+		require.Len(t, instance.Attachments, 2)
+
+		file, err := instance.Attachments[1].Open()
+		require.NoError(t, err)
+
+		defer func() { _ = file.Close() }()
+
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "two", string(content))
+	})
+	t.Run("temporary_files_are_removed_by_the_handler", func(t *testing.T) {
+		t.Parallel()
+
+		sh := GetSchemaHelper[*smallUploadRequest]()
+		assert.Equal(t, int64(1), sh.maxMemory)
+
+		var tempFile string
+
+		handler := CreateSimpleHandler(func(_ context.Context, request *smallUploadRequest) (string, int, error) {
+			file, err := request.Avatar.Open()
+			if err != nil {
+				return "", 0, err
+			}
+
+			defer func() { _ = file.Close() }()
+
+			if osFile, ok := file.(*os.File); ok {
+				tempFile = osFile.Name()
+			}
+
+			return "ok", http.StatusOK, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler(w, newMultipartRequest(t, nil, map[string][]string{"avatar": {"a content larger than 1 byte"}}))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.NotEmpty(t, tempFile, "file should be stored in a temporary file")
+		assert.NoFileExists(t, tempFile)
+	})
+	t.Run("invalid_file_field_type_should_panic", func(t *testing.T) {
+		t.Parallel()
+		assert.Panics(t, func() {
+			_ = GetSchemaHelper[*invalidUploadRequest]()
+		})
+	})
+}
@@ -0,0 +1,261 @@
+package typedhandler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// ResponseEncoder encodes a response value into the response body
+	ResponseEncoder interface {
+		// CanEncode checks if the value can be encoded
+		CanEncode(v any) bool
+		Encode(w io.Writer, v any) error
+	}
+
+	// TextEncoder encodes string and fmt.Stringer responses as plain text
+	TextEncoder struct{}
+
+	// NotAcceptableError is returned when there is no ResponseEncoder for the Accept header of the request
+	NotAcceptableError struct {
+		Accept string
+	}
+
+	// mediaRange is a media range of the Accept header (e.g. "text/*;q=0.5")
+	mediaRange struct {
+		name    string  // media range, without parameters
+		quality float64 // q parameter, 1 by default
+	}
+
+	registeredEncoder struct {
+		mediaType   string // media type, without parameters
+		contentType string // media type, with parameters
+		encoder     ResponseEncoder
+	}
+)
+
+const (
+	textMediaType = "text/plain"
+	utf8Charset   = "; charset=utf-8"
+)
+
+var (
+	// responseEncoders in order of preference, when the client accepts any media type
+	responseEncoders = []registeredEncoder{
+		{jsonMediaType, jsonMediaType + utf8Charset, JSONCodec{}},
+		{xmlMediaType, xmlMediaType + utf8Charset, XMLCodec{}},
+		{textMediaType, textMediaType + utf8Charset, TextEncoder{}},
+		{textXMLMediaType, textXMLMediaType + utf8Charset, XMLCodec{}},
+	}
+	responseEncodersLock sync.RWMutex
+
+	bufferPool = sync.Pool{
+		New: func() any { return new(bytes.Buffer) },
+	}
+)
+
+// RegisterResponseEncoder registers the encoder used for responses with the content type
+// (e.g. "application/yaml" or "text/csv; charset=utf-8").
+// A registered encoder replaces the previous encoder for the same media type,
+// new media types have the lowest preference when the client accepts any media type
+func RegisterResponseEncoder(contentType string, encoder ResponseEncoder) {
+	responseEncodersLock.Lock()
+	defer responseEncodersLock.Unlock()
+
+	// the encoders are copied, the slices returned by registeredEncoders are never changed
+	responseEncoders = setEncoder(slices.Clone(responseEncoders), newRegisteredEncoder(contentType, encoder))
+}
+
+// registeredEncoders returns the registered encoders, in order of preference
+func registeredEncoders() []registeredEncoder {
+	responseEncodersLock.RLock()
+	defer responseEncodersLock.RUnlock()
+
+	return responseEncoders
+}
+
+// newRegisteredEncoder creates the registeredEncoder of the encoder for the content type
+func newRegisteredEncoder(contentType string, encoder ResponseEncoder) registeredEncoder {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+
+	return registeredEncoder{
+		mediaType:   strings.ToLower(strings.TrimSpace(mediaType)),
+		contentType: contentType,
+		encoder:     encoder,
+	}
+}
+
+// setEncoder replaces the encoder of the same media type in the encoders, or appends it
+func setEncoder(encoders []registeredEncoder, registered registeredEncoder) []registeredEncoder {
+	for i := range encoders {
+		if encoders[i].mediaType == registered.mediaType {
+			encoders[i] = registered
+			return encoders
+		}
+	}
+
+	return append(encoders, registered)
+}
+
+// putBuffer resets the buffer and returns it to the pool
+func putBuffer(buffer *bytes.Buffer) {
+	buffer.Reset()
+	bufferPool.Put(buffer)
+}
+
+// negotiateEncoder returns the encoder for the response, chosen by the Accept header of the request
+// Each encoder takes the quality of the most specific media range that matches its media type
+// (e.g. "application/json" before "application/*" before "*/*"), and media types with quality 0 are refused.
+// The encoders are ranked by their quality, then by the order of their media range in the header.
+// A request without an Accept header accepts any media type.
+// The preferred encoders (from the Config of the handler) are tried before the registered encoders
+func negotiateEncoder(accept string, response any, preferred []registeredEncoder) (registeredEncoder, error) {
+	return negotiate(accept, preferred, func(encoder ResponseEncoder) bool { return encoder.CanEncode(response) })
+}
+
+// checkAcceptable returns a NotAcceptableError when no encoder has a media type accepted by the Accept header,
+// whatever the response is. It is checked before the service is called
+func checkAcceptable(accept string, preferred []registeredEncoder) error {
+	if accept == "" {
+		return nil
+	}
+
+	_, err := negotiate(accept, preferred, func(ResponseEncoder) bool { return true })
+
+	return err
+}
+
+// negotiate returns the best encoder for the Accept header among the encoders that canEncode accepts
+// The encoders run without the lock, so they can register other encoders
+func negotiate(
+	accept string, preferred []registeredEncoder, canEncode func(ResponseEncoder) bool,
+) (registeredEncoder, error) {
+	if accept == "" {
+		accept = "*/*"
+	}
+
+	var (
+		buffer      [8]mediaRange // most Accept headers fit, without allocating
+		ranges      = parseAccept(buffer[:0], accept)
+		best        registeredEncoder
+		bestQuality = 0.0
+		bestOrder   = len(ranges)
+	)
+
+	for _, encoders := range [][]registeredEncoder{preferred, registeredEncoders()} {
+		for _, registered := range encoders {
+			order := matchMediaRange(ranges, registered.mediaType)
+			if order < 0 {
+				continue
+			}
+
+			quality := ranges[order].quality
+			if quality > bestQuality || (quality == bestQuality && quality > 0 && order < bestOrder) {
+				if canEncode(registered.encoder) {
+					best, bestQuality, bestOrder = registered, quality, order
+				}
+			}
+		}
+	}
+
+	if bestQuality == 0 {
+		return best, NotAcceptableError{Accept: accept}
+	}
+
+	return best, nil
+}
+
+// parseAccept appends the media ranges of the Accept header to ranges, in the order of the header
+func parseAccept(ranges []mediaRange, accept string) []mediaRange {
+	for value := range strings.SplitSeq(accept, ",") {
+		ranges = append(ranges, parseMediaRange(value))
+	}
+
+	return ranges
+}
+
+// parseMediaRange returns the media range (e.g. "text/*") and its quality (q parameter, default 1)
+func parseMediaRange(value string) mediaRange {
+	name, params, _ := strings.Cut(value, ";")
+	quality := 1.0
+
+	for param := range strings.SplitSeq(params, ";") {
+		name, q, _ := strings.Cut(param, "=")
+		if strings.TrimSpace(name) != "q" {
+			continue
+		}
+
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(q), bit64); err == nil {
+			quality = parsed
+		}
+	}
+
+	return mediaRange{name: strings.ToLower(strings.TrimSpace(name)), quality: quality}
+}
+
+// matchMediaRange returns the index of the most specific media range that matches the media type, or -1
+func matchMediaRange(ranges []mediaRange, mediaType string) int {
+	match, specificity := -1, -1
+
+	for i, mediaRange := range ranges {
+		rangeSpecificity := mediaRange.specificity(mediaType)
+		if rangeSpecificity > specificity {
+			match, specificity = i, rangeSpecificity
+		}
+	}
+
+	return match
+}
+
+// specificity returns how specifically the media range matches the media type:
+// 2 for the media type, 1 for its type wildcard (e.g. "text/*"), 0 for "*/*", -1 if it does not match
+func (m mediaRange) specificity(mediaType string) int {
+	switch {
+	case m.name == mediaType:
+		return 2
+	case m.name == "*/*":
+		return 0
+	}
+
+	if prefix, wildcard := strings.CutSuffix(m.name, "*"); wildcard && strings.HasPrefix(mediaType, prefix) {
+		return 1
+	}
+
+	return -1
+}
+
+func (TextEncoder) CanEncode(v any) bool {
+	switch v.(type) {
+	case string, fmt.Stringer:
+		return true
+	default:
+		return false
+	}
+}
+
+func (TextEncoder) Encode(w io.Writer, v any) (err error) {
+	switch value := v.(type) {
+	case string:
+		_, err = io.WriteString(w, value)
+	case fmt.Stringer:
+		_, err = io.WriteString(w, value.String())
+	default:
+		err = fmt.Errorf("text response must be a string or a fmt.Stringer, not %s", typeString(v))
+	}
+
+	return err
+}
+
+func (e NotAcceptableError) Error() string {
+	return "no acceptable response media type for: " + e.Accept
+}
+
+func (e NotAcceptableError) Status() int {
+	return http.StatusNotAcceptable
+}
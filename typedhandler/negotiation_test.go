@@ -0,0 +1,128 @@
+package typedhandler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type csvEncoder struct{}
+
+func (csvEncoder) CanEncode(v any) bool {
+	_, ok := v.([][]string)
+	return ok
+}
+
+func (csvEncoder) Encode(w io.Writer, v any) error {
+	return csv.NewWriter(w).WriteAll(v.([][]string))
+}
+
+func Test_negotiateEncoder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		accept      string
+		response    any
+		contentType string
+	}{
+		{"no_accept", "", struct{}{}, "application/json; charset=utf-8"},
+		{"any", "*/*", "text", "application/json; charset=utf-8"},
+		{"json", "application/json", struct{}{}, "application/json; charset=utf-8"},
+		{"text_xml", "text/xml", struct{}{}, "text/xml; charset=utf-8"},
+		{"wildcard_subtype", "application/*", struct{}{}, "application/json; charset=utf-8"},
+		{"quality", "application/json;q=0.5, application/xml;q=0.8", struct{}{}, "application/xml; charset=utf-8"},
+		{"header_order", "text/plain, application/json", "text", "text/plain; charset=utf-8"},
+		{"stringer", "text/plain", time.Second, "text/plain; charset=utf-8"},
+		{"text_not_encodable", "text/plain, application/json;q=0.1", struct{}{}, "application/json; charset=utf-8"},
+		{"refused", "application/json;q=0, */*", struct{}{}, "application/xml; charset=utf-8"},
+		{"most_specific", "text/*;q=0.9, text/plain;q=0.1", "text", "text/xml; charset=utf-8"},
+		{"xml_not_encodable", "application/xml, application/json;q=0.5", map[string]int{}, "application/json; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := negotiateEncoder(tt.accept, tt.response, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.contentType, got.contentType)
+		})
+	}
+
+	t.Run("not_acceptable", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := negotiateEncoder("image/png, */*;q=0", struct{}{}, nil)
+		require.ErrorAs(t, err, &NotAcceptableError{})
+
+		_, err = negotiateEncoder("application/xml", map[string]int{}, nil)
+		require.ErrorAs(t, err, &NotAcceptableError{})
+	})
+}
+
+func TestRegisterResponseEncoder(t *testing.T) {
+	t.Parallel()
+
+	RegisterResponseEncoder("text/csv; charset=utf-8", csvEncoder{})
+
+	got, err := negotiateEncoder("text/csv", [][]string{{"a", "b"}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", got.contentType)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, got.encoder.Encode(buffer, [][]string{{"a", "b"}}))
+	assert.Equal(t, "a,b\n", buffer.String())
+
+	_, err = negotiateEncoder("text/csv", "not a table", nil)
+	require.Error(t, err)
+}
+
+// registeringEncoder registers an encoder when CanEncode is called
+type registeringEncoder struct{ csvEncoder }
+
+func (registeringEncoder) CanEncode(v any) bool {
+	RegisterResponseEncoder("text/tab-separated-values", csvEncoder{})
+	return true
+}
+
+func TestRegisterResponseEncoder_fromCanEncode(t *testing.T) {
+	t.Parallel()
+
+	RegisterResponseEncoder("text/x-registering", registeringEncoder{})
+
+	done := make(chan error)
+	go func() {
+		_, err := negotiateEncoder("text/x-registering", [][]string{}, nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "CanEncode deadlocked registering an encoder")
+	}
+}
+
+func Test_checkAcceptable(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, checkAcceptable("", nil))
+	require.NoError(t, checkAcceptable("text/plain", nil)) // the response is not checked
+	require.NoError(t, checkAcceptable("image/png", []registeredEncoder{newRegisteredEncoder("image/png", csvEncoder{})}))
+	require.ErrorAs(t, checkAcceptable("image/png, */*;q=0", nil), &NotAcceptableError{})
+}
+
+func TestTextEncoder_Encode(t *testing.T) {
+	t.Parallel()
+
+	buffer := &bytes.Buffer{}
+	require.Error(t, TextEncoder{}.Encode(buffer, 1))
+	require.NoError(t, TextEncoder{}.Encode(buffer, time.Minute))
+	assert.Equal(t, "1m0s", buffer.String())
+}
@@ -0,0 +1,441 @@
+package typedhandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// Registry records the routes of the handlers created by Register or with WithRoute, to generate their OpenAPI
+	// document
+	Registry struct {
+		mu     sync.Mutex
+		routes []route
+	}
+
+	// route is a handler recorded in a Registry
+	route struct {
+		method    string
+		path      string
+		operation func(g *schemaGenerator) *OpenAPIOperation
+	}
+
+	// OpenAPIDocument is an OpenAPI 3.1 document
+	OpenAPIDocument struct {
+		OpenAPI    string                     `json:"openapi"`
+		Info       OpenAPIInfo                `json:"info"`
+		Paths      map[string]OpenAPIPathItem `json:"paths"`
+		Components *OpenAPIComponents         `json:"components,omitempty"`
+	}
+
+	// OpenAPIInfo is the metadata of the API
+	OpenAPIInfo struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// OpenAPIPathItem holds the operations of a path by lowercase HTTP method
+	OpenAPIPathItem map[string]*OpenAPIOperation
+
+	// OpenAPIOperation describes a single API operation on a path
+	OpenAPIOperation struct {
+		Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+		RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*OpenAPIResponse `json:"responses"`
+	}
+
+	// OpenAPIParameter describes a path, query, header or cookie parameter
+	OpenAPIParameter struct {
+		Name     string      `json:"name"`
+		In       string      `json:"in"`
+		Required bool        `json:"required,omitempty"`
+		Style    string      `json:"style,omitempty"`
+		Explode  *bool       `json:"explode,omitempty"`
+		Schema   *JSONSchema `json:"schema"`
+	}
+
+	// OpenAPIRequestBody describes the request body by media type
+	OpenAPIRequestBody struct {
+		Required bool                        `json:"required,omitempty"`
+		Content  map[string]OpenAPIMediaType `json:"content"`
+	}
+
+	// OpenAPIResponse describes a response by media type
+	OpenAPIResponse struct {
+		Description string                      `json:"description"`
+		Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+	}
+
+	// OpenAPIMediaType holds the schema of a media type
+	OpenAPIMediaType struct {
+		Schema *JSONSchema `json:"schema"`
+	}
+
+	// OpenAPIComponents holds the schemas referenced by the document
+	OpenAPIComponents struct {
+		Schemas map[string]*JSONSchema `json:"schemas,omitempty"`
+	}
+)
+
+const openAPIVersion = "3.1.0"
+
+var (
+	// DefaultRegistry is the registry used by the applications that don't need more than one API document
+	DefaultRegistry = NewRegistry()
+
+	// patternMethods are the methods of the OpenAPI operations, recorded for the patterns without a method
+	patternMethods = []string{
+		http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+		http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+	}
+)
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register creates the handler for serviceFunc (like CreateSimpleHandler) and records its route in the registry
+// The pattern is the same used to register the handler in the http.ServeMux:
+//
+//	mux.HandleFunc("GET /users/{id}", typedhandler.Register(registry, "GET /users/{id}", getUser))
+//
+// Patterns without a method match every method, so they are recorded for every method.
+// Register is CreateSimpleHandler with the WithRoute option: the route is described by the schema helper
+// of the parser of the handler
+func Register[RIn RequestSchema, ROut ResponseSchema](
+	registry *Registry, pattern string, serviceFunc ServiceFunc[RIn, ROut], options ...Option,
+) HandlerFunc {
+	return CreateSimpleHandler(serviceFunc, append(slices.Clone(options), WithRoute(registry, pattern))...)
+}
+
+// RecordRoute records the route of a handler created with CreateHandler in the registry
+// A route recorded again with the same method and path replaces the previous one.
+// Pass the options of the parser and of the handler, so the route is described by the same schema
+// and error responses
+func RecordRoute[RIn RequestSchema, ROut ResponseSchema](registry *Registry, pattern string, options ...Option) {
+	config := NewConfig(options...)
+	recordRoute[RIn, ROut](registry, pattern, getSchemaHelper[RIn](config), config)
+}
+
+// recordRoute records the route described by the schema helper and the settings of the config in the registry
+func recordRoute[RIn RequestSchema, ROut ResponseSchema](registry *Registry, pattern string,
+	schemaHelper *SchemaHelper[RIn], config *Config,
+) {
+	method, path := splitPattern(pattern)
+
+	methods := []string{method}
+	if method == "" {
+		methods = patternMethods
+	}
+
+	operation := func(g *schemaGenerator) *OpenAPIOperation {
+		return &OpenAPIOperation{
+			Parameters:  schemaHelper.openAPIParameters(g),
+			RequestBody: schemaHelper.openAPIRequestBody(g),
+			Responses:   openAPIResponses[ROut](g, schemaHelper.bodyType != NoBody, config),
+		}
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for _, method := range methods {
+		registry.routes = slices.DeleteFunc(registry.routes, func(r route) bool {
+			return r.method == method && r.path == path
+		})
+		registry.routes = append(registry.routes, route{method: method, path: path, operation: operation})
+	}
+}
+
+// Document generates the OpenAPI 3.1 document of the routes recorded in the registry
+func (registry *Registry) Document(info OpenAPIInfo) *OpenAPIDocument {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	g := newSchemaGenerator()
+	document := &OpenAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]OpenAPIPathItem, len(registry.routes)),
+	}
+
+	for _, route := range registry.routes {
+		pathItem, found := document.Paths[route.path]
+		if !found {
+			pathItem = make(OpenAPIPathItem)
+			document.Paths[route.path] = pathItem
+		}
+
+		pathItem[strings.ToLower(route.method)] = route.operation(g)
+	}
+
+	if len(g.schemas) > 0 {
+		document.Components = &OpenAPIComponents{Schemas: g.schemas}
+	}
+
+	return document
+}
+
+// Handler returns a handler that serves the OpenAPI document of the registry as JSON
+// The document is generated on each request, so it includes routes recorded after the handler was created
+func (registry *Registry) Handler(info OpenAPIInfo) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := json.Marshal(registry.Document(info))
+		if err != nil {
+			writeErrorResponse(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	}
+}
+
+// openAPIParameters returns the path, query, header and cookie parameters of RIn
+// Form fields are query parameters when the request has no form body
+func (sh *SchemaHelper[RIn]) openAPIParameters(g *schemaGenerator) []OpenAPIParameter {
+	sources := []struct {
+		in       string
+		bindings []fieldBinding
+	}{
+		{"path", sh.pathFields},
+		{"query", sh.queryFields},
+		{"header", sh.headerFields},
+		{"cookie", sh.cookieFields},
+	}
+	if sh.bodyType != FormBody && sh.bodyType != MultipartBody {
+		sources = append(sources, struct {
+			in       string
+			bindings []fieldBinding
+		}{"query", sh.formFields})
+	}
+
+	var parameters []OpenAPIParameter
+
+	for _, source := range sources {
+		for _, binding := range source.bindings {
+			if source.in == "header" && isReservedHeader(binding.name) {
+				continue
+			}
+
+			parameters = append(parameters, sh.openAPIParameter(g, source.in, &binding))
+		}
+	}
+
+	return parameters
+}
+
+// openAPIParameter returns the parameter of a field binding
+func (sh *SchemaHelper[RIn]) openAPIParameter(g *schemaGenerator, in string, binding *fieldBinding) OpenAPIParameter {
+	field := getType[RIn]().FieldByIndex(binding.index)
+
+	schema := &JSONSchema{Type: "string"} // *http.Cookie fields receive the cookie value
+	if !binding.httpCookie {
+		schema = g.paramSchema(field.Type)
+	}
+
+	required := applyValidateTag(&schema, field.Type, field.Tag.Get("validate"))
+	schema.Default = openAPIDefault(binding, valueType(field.Type))
+	parameter := OpenAPIParameter{
+		Name:     binding.name,
+		In:       in,
+		Required: required || in == "path", // path parameters are always required
+		Schema:   schema,
+	}
+
+	if binding.multi && !binding.explode {
+		parameter.Style, parameter.Explode = "form", ptr(false)
+	}
+
+	return parameter
+}
+
+// openAPIRequestBody returns the request body of RIn, or nil if RIn has no body
+func (sh *SchemaHelper[RIn]) openAPIRequestBody(g *schemaGenerator) *OpenAPIRequestBody {
+	var mediaType string
+
+	schema := &JSONSchema{}
+
+	switch sh.bodyType {
+	case JsonBody:
+		mediaType, schema = "application/json", sh.jsonBodySchema(g)
+	case JsonField:
+		mediaType, schema = "application/json", g.schema(sh.bodyFieldType)
+		if isRawBody(sh.bodyFieldType.Elem()) {
+			mediaType, schema = "application/octet-stream", &JSONSchema{Type: "string", Format: "binary"}
+		}
+	case FormBody:
+		mediaType, schema = urlencodedMediaType, sh.formSchema(g)
+	case MultipartBody:
+		mediaType, schema = multipartMediaType, sh.formSchema(g)
+	default:
+		return nil
+	}
+
+	return &OpenAPIRequestBody{
+		Required: true,
+		Content:  map[string]OpenAPIMediaType{mediaType: {Schema: schema}},
+	}
+}
+
+// jsonBodySchema returns the object schema of the JSON body of RIn, with the encoding/json rules
+// The fields of the parameters without a json tag are described by the parameters
+func (sh *SchemaHelper[RIn]) jsonBodySchema(g *schemaGenerator) *JSONSchema {
+	t := getType[RIn]()
+	schema := g.structSchema(t)
+
+	for _, binding := range slices.Concat(sh.pathFields, sh.queryFields, sh.headerFields, sh.cookieFields,
+		sh.formFields) {
+		field := t.FieldByIndex(binding.index)
+		if _, tagged := field.Tag.Lookup("json"); !tagged {
+			delete(schema.Properties, field.Name)
+			schema.Required = slices.DeleteFunc(schema.Required, func(name string) bool { return name == field.Name })
+		}
+	}
+
+	return schema
+}
+
+// formSchema returns the object schema of the form and file fields of RIn
+// Files are binary strings
+func (sh *SchemaHelper[RIn]) formSchema(g *schemaGenerator) *JSONSchema {
+	schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+	t := getType[RIn]()
+
+	for _, binding := range slices.Concat(sh.formFields, sh.fileFields) {
+		field := t.FieldByIndex(binding.index)
+
+		property := &JSONSchema{Type: "string", Format: "binary"}
+		if field.Type == fileHeadersType {
+			property = &JSONSchema{Type: "array", Items: property}
+		} else if field.Type != fileHeaderType {
+			property = g.paramSchema(field.Type)
+		}
+
+		if applyValidateTag(&property, field.Type, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, binding.name)
+		}
+
+		property.Default = openAPIDefault(&binding, valueType(field.Type))
+
+		schema.Properties[binding.name] = property
+	}
+
+	return schema
+}
+
+// openAPIResponses returns the response of ROut and the error responses written by the handler:
+// 400 (parse and validation errors), 406 (no encoder for the Accept header) and, for requests with a body,
+// 415 (unsupported media type) and 413 (body larger than WithMaxBodySize)
+func openAPIResponses[ROut ResponseSchema](
+	g *schemaGenerator, hasBody bool, config *Config,
+) map[string]*OpenAPIResponse {
+	t := reflect.TypeFor[ROut]()
+
+	mediaType := "application/json"
+	if t.Kind() == reflect.String {
+		mediaType = "text/plain"
+	}
+
+	responses := map[string]*OpenAPIResponse{
+		"200": {
+			Description: http.StatusText(http.StatusOK),
+			Content:     map[string]OpenAPIMediaType{mediaType: {Schema: g.schema(t)}},
+		},
+	}
+
+	statuses := []int{http.StatusBadRequest, http.StatusNotAcceptable}
+	if hasBody {
+		statuses = append(statuses, http.StatusUnsupportedMediaType)
+	}
+
+	if hasBody && config.maxBodySize > 0 {
+		statuses = append(statuses, http.StatusRequestEntityTooLarge)
+	}
+
+	for _, status := range statuses {
+		responses[strconv.Itoa(status)] = &OpenAPIResponse{
+			Description: http.StatusText(status),
+			Content:     openAPIErrorContent(g, status, config),
+		}
+	}
+
+	return responses
+}
+
+// openAPIErrorContent returns the content of the error responses with the status: problem details when they are
+// enabled, a ValidationErrorResponse or a message for bad requests, and a message for the other statuses.
+// The content of the responses written by an ErrorRenderer is unknown
+func openAPIErrorContent(g *schemaGenerator, status int, config *Config) map[string]OpenAPIMediaType {
+	message := OpenAPIMediaType{Schema: &JSONSchema{Type: "string"}}
+
+	switch {
+	case config.errorRenderer != nil:
+		return nil
+	case config.problemDetailsEnabled():
+		return map[string]OpenAPIMediaType{problemMediaType: {Schema: g.schema(reflect.TypeFor[ProblemDetails]())}}
+	case status == http.StatusBadRequest:
+		return map[string]OpenAPIMediaType{
+			"application/json": {Schema: g.schema(reflect.TypeFor[ValidationErrorResponse]())},
+			textMediaType:      message,
+		}
+	default:
+		return map[string]OpenAPIMediaType{textMediaType: message}
+	}
+}
+
+// openAPIDefault returns the value of the "default" tag of the binding, converted to the JSON type of the field,
+// or nil if the binding has no default
+func openAPIDefault(binding *fieldBinding, t reflect.Type) any {
+	switch {
+	case !binding.hasDefault:
+		return nil
+	case binding.multi:
+		values := make([]any, len(binding.defaults))
+		for i, value := range binding.defaults {
+			values[i] = enumValue(t.Elem(), value)
+		}
+
+		return values
+	default:
+		return enumValue(t, firstValue(binding.defaults))
+	}
+}
+
+// splitPattern returns the method (empty for patterns without a method) and the OpenAPI path
+// of a http.ServeMux pattern
+// The host is removed, wildcards "{name...}" become "{name}" and the "{$}" suffix is removed
+func splitPattern(pattern string) (method, path string) {
+	method, path, found := strings.Cut(strings.TrimSpace(pattern), " ")
+	if !found {
+		method, path = "", method
+	}
+
+	path = strings.TrimSpace(path)
+	if i := strings.Index(path, "/"); i > 0 {
+		path = path[i:]
+	}
+
+	path = strings.TrimSuffix(path, "{$}")
+	path = strings.ReplaceAll(path, "...}", "}")
+
+	return strings.ToUpper(method), path
+}
+
+// isReservedHeader checks if the header is described by other fields of the OpenAPI document
+func isReservedHeader(name string) bool {
+	switch http.CanonicalHeaderKey(name) {
+	case "Accept", "Content-Type", "Authorization":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,371 @@
+package typedhandler
+
+import (
+	"encoding"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	// JSONSchema is the subset of JSON Schema (draft 2020-12) used by OpenAPI 3.1 documents
+	JSONSchema struct {
+		Ref                  string                 `json:"$ref,omitempty"`
+		Type                 string                 `json:"type,omitempty"`
+		Format               string                 `json:"format,omitempty"`
+		Items                *JSONSchema            `json:"items,omitempty"`
+		Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+		AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+		Required             []string               `json:"required,omitempty"`
+		Enum                 []any                  `json:"enum,omitempty"`
+		Default              any                    `json:"default,omitempty"`
+		Minimum              *float64               `json:"minimum,omitempty"`
+		Maximum              *float64               `json:"maximum,omitempty"`
+		ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty"`
+		MinLength            *uint64                `json:"minLength,omitempty"`
+		MaxLength            *uint64                `json:"maxLength,omitempty"`
+		MinItems             *uint64                `json:"minItems,omitempty"`
+		MaxItems             *uint64                `json:"maxItems,omitempty"`
+	}
+
+	// schemaGenerator creates JSON schemas from Go types
+	// Named struct types are stored as components and referenced by name
+	schemaGenerator struct {
+		schemas map[string]*JSONSchema
+		names   map[reflect.Type]string
+	}
+)
+
+var (
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+	// validatorFormats maps validator tags to JSON schema formats
+	validatorFormats = map[string]string{
+		"email":    "email",
+		"url":      "uri",
+		"uri":      "uri",
+		"http_url": "uri",
+		"uuid":     "uuid",
+		"uuid4":    "uuid",
+		"ipv4":     "ipv4",
+		"ipv6":     "ipv6",
+		"hostname": "hostname",
+		"datetime": "date-time",
+	}
+
+	componentNameReplacer = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: make(map[string]*JSONSchema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// paramSchema returns the schema of a parameter (path, query, header, cookie or form value) of type t
+// Parameters are converted from strings, so time.Duration values are strings like "1h30m",
+// and the types with registered converters or encoding.TextUnmarshaler implementations are strings
+func (g *schemaGenerator) paramSchema(t reflect.Type) *JSONSchema {
+	if registeredConverter(t) != nil {
+		return &JSONSchema{Type: "string"}
+	}
+
+	if value := optionalType(t); value != nil {
+		return g.paramSchema(value)
+	}
+
+	switch {
+	case t == durationType:
+		return &JSONSchema{Type: "string", Format: "duration"}
+	case t != timeType && reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return &JSONSchema{Type: "string"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		schema := &JSONSchema{Type: "array", Items: g.paramSchema(t.Elem())}
+		if t.Kind() == reflect.Array {
+			schema.MaxItems = ptr(uint64(t.Len()))
+		}
+
+		return schema
+	default:
+		return g.schema(t)
+	}
+}
+
+// schema returns the schema of a JSON value of type t
+func (g *schemaGenerator) schema(t reflect.Type) *JSONSchema {
+	t = valueType(t)
+
+	if schema := scalarSchema(t); schema != nil {
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string", Format: "byte"}
+		}
+
+		return &JSONSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+
+		return g.componentRef(t)
+	default:
+		// interfaces and other types accept any value
+		return &JSONSchema{}
+	}
+}
+
+// componentRef returns a reference to the component schema of the named struct type t,
+// creating the component on the first use
+func (g *schemaGenerator) componentRef(t reflect.Type) *JSONSchema {
+	name, found := g.names[t]
+	if !found {
+		name = componentNameReplacer.ReplaceAllString(t.Name(), "_")
+		if _, taken := g.schemas[name]; taken {
+			name = componentNameReplacer.ReplaceAllString(t.PkgPath()+"."+t.Name(), "_")
+		}
+
+		g.names[t] = name
+		g.schemas[name] = &JSONSchema{} // placeholder for recursive types
+		*g.schemas[name] = *g.structSchema(t)
+	}
+
+	return &JSONSchema{Ref: "#/components/schemas/" + name}
+}
+
+// structSchema returns the object schema of the struct type t, following the encoding/json rules
+// for field names and embedded structs: exported fields without a json tag have their Go name
+func (g *schemaGenerator) structSchema(t reflect.Type) *JSONSchema {
+	schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+	g.addStructFields(schema, t)
+
+	return schema
+}
+
+func (g *schemaGenerator) addStructFields(schema *JSONSchema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, options, _ := strings.Cut(tag, ",")
+
+		if embedded := embeddedJSONStruct(&field, name); embedded != nil {
+			g.addStructFields(schema, embedded)
+			continue
+		}
+
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if _, exists := schema.Properties[name]; exists {
+			// fields of the outer struct hide the fields of embedded structs
+			continue
+		}
+
+		fieldSchema := g.schema(field.Type)
+		if applyValidateTag(&fieldSchema, field.Type, field.Tag.Get("validate")) &&
+			!strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = fieldSchema
+	}
+}
+
+// scalarSchema returns the schema of the types converted from a single JSON value, or nil
+func scalarSchema(t reflect.Type) *JSONSchema {
+	switch {
+	case t == timeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType),
+		reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return &JSONSchema{Type: "string"}
+	}
+
+	kind := t.Kind()
+
+	switch {
+	case kind == reflect.String:
+		return &JSONSchema{Type: "string"}
+	case kind == reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case isIntKind(kind):
+		return &JSONSchema{Type: "integer", Format: integerFormat(kind)}
+	case isUintKind(kind):
+		return &JSONSchema{Type: "integer", Format: integerFormat(kind), Minimum: ptr(0.0)}
+	case kind == reflect.Float32:
+		return &JSONSchema{Type: "number", Format: "float"}
+	case kind == reflect.Float64:
+		return &JSONSchema{Type: "number", Format: "double"}
+	default:
+		return nil
+	}
+}
+
+// integerFormat returns the OpenAPI format of the integer kind
+func integerFormat(kind reflect.Kind) string {
+	if getBitSize(kind) <= bit32 {
+		return "int32"
+	}
+
+	return "int64"
+}
+
+// embeddedJSONStruct returns the struct type of an embedded field flattened by encoding/json, or nil
+func embeddedJSONStruct(field *reflect.StructField, jsonName string) reflect.Type {
+	if !field.Anonymous || jsonName != "" {
+		return nil
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || (!field.IsExported() && field.Type.Kind() == reflect.Pointer) {
+		return nil
+	}
+
+	return t
+}
+
+// applyValidateTag maps the rules of the validate tag into constraints of the schema of type t,
+// and returns true if the value is required
+// Rules after "dive" apply to the items of arrays
+func applyValidateTag(schema **JSONSchema, t reflect.Type, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+
+	t = valueType(t)
+
+	rules, itemRules, dive := strings.Cut(tag, ",dive")
+	if dive && (*schema).Items != nil {
+		itemRules = strings.TrimPrefix(itemRules, ",")
+		applyValidateTag(&(*schema).Items, t.Elem(), itemRules)
+	}
+
+	target := *schema
+	if target.Ref != "" {
+		// constraints of referenced schemas are siblings of the $ref
+		target = &JSONSchema{Ref: target.Ref}
+		*schema = target
+	}
+
+	for rule := range strings.SplitSeq(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "required" {
+			required = true
+			continue
+		}
+
+		applyValidateRule(target, t, name, param)
+	}
+
+	return required
+}
+
+// applyValidateRule maps a validator rule into a constraint of the schema
+func applyValidateRule(schema *JSONSchema, t reflect.Type, name, param string) {
+	if format, ok := validatorFormats[name]; ok {
+		schema.Format = format
+		return
+	}
+
+	switch name {
+	case "oneof":
+		for value := range strings.FieldsSeq(param) {
+			schema.Enum = append(schema.Enum, enumValue(t, value))
+		}
+	case "min", "max", "len", "gte", "lte", "gt", "lt":
+		if number, err := strconv.ParseFloat(param, bit64); err == nil {
+			applyLimit(schema, t, name, number)
+		}
+	}
+}
+
+// applyLimit maps a limit rule into the constraint for the kind of the type:
+// length for strings, number of items for arrays, slices and maps, and value for numbers
+func applyLimit(schema *JSONSchema, t reflect.Type, name string, limit float64) {
+	var minimum, maximum **uint64
+
+	switch t.Kind() {
+	case reflect.String:
+		minimum, maximum = &schema.MinLength, &schema.MaxLength
+	case reflect.Slice, reflect.Array, reflect.Map:
+		minimum, maximum = &schema.MinItems, &schema.MaxItems
+	default:
+		if t == durationType || t == timeType {
+			return
+		}
+
+		switch name {
+		case "min", "gte":
+			schema.Minimum = ptr(limit)
+		case "max", "lte":
+			schema.Maximum = ptr(limit)
+		case "gt":
+			schema.ExclusiveMinimum = ptr(limit)
+		case "lt":
+			schema.ExclusiveMaximum = ptr(limit)
+		case "len":
+			schema.Minimum, schema.Maximum = ptr(limit), ptr(limit)
+		}
+
+		return
+	}
+
+	count := uint64(max(limit, 0))
+
+	switch name {
+	case "min", "gte":
+		*minimum = ptr(count)
+	case "max", "lte":
+		*maximum = ptr(count)
+	case "gt":
+		*minimum = ptr(count + 1)
+	case "lt":
+		*maximum = ptr(max(count, 1) - 1)
+	case "len":
+		*minimum, *maximum = ptr(count), ptr(count)
+	}
+}
+
+// enumValue converts a oneof value to the JSON type of the field
+func enumValue(t reflect.Type, value string) any {
+	kind := t.Kind()
+
+	switch {
+	case isIntKind(kind) && t != durationType:
+		if number, err := strconv.ParseInt(value, 10, bit64); err == nil {
+			return number
+		}
+	case isUintKind(kind):
+		if number, err := strconv.ParseUint(value, 10, bit64); err == nil {
+			return number
+		}
+	case isFloatKind(kind):
+		if number, err := strconv.ParseFloat(value, bit64); err == nil {
+			return number
+		}
+	}
+
+	return value
+}
+
+// ptr returns a pointer to the value
+func ptr[T any](value T) *T {
+	return &value
+}
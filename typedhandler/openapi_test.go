@@ -0,0 +1,319 @@
+package typedhandler
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	apiAddress struct {
+		Street string `json:"street" validate:"required"`
+	}
+	apiUser struct {
+		ID       int         `json:"id"`
+		Email    string      `json:"email"   validate:"required,email"`
+		Age      uint8       `json:"age"     validate:"gte=18,lte=130"`
+		Role     string      `json:"role"    validate:"oneof=admin user"`
+		Tags     []string    `json:"tags"    validate:"max=5,dive,min=2"`
+		Created  time.Time   `json:"created"`
+		Address  *apiAddress `json:"address,omitempty"`
+		Password string      `json:"-"`
+	}
+	apiUpdateUserRequest struct {
+		ID      int           `path:"id"`
+		Fields  []string      `query:"fields,explode=false"`
+		Timeout time.Duration `query:"timeout"`
+		Tenant  string        `header:"X-Tenant" validate:"required"`
+		Accept  string        `header:"Accept"`
+		Session string        `cookie:"session"`
+		Name    string        `json:"name"         validate:"min=3,max=50"`
+		Email   string        `json:"email"        validate:"required,email"`
+		Note    string        // decoded from the body by its Go name
+		Secret  string        `json:"-"`
+	}
+	apiUserBodyRequest struct {
+		ID   int     `path:"id"`
+		User apiUser `body:"user"`
+	}
+)
+
+func (r *apiUserBodyRequest) GetBodyField() any {
+	return &r.User
+}
+
+func apiUpdateUser(context.Context, *apiUpdateUserRequest) (*apiUser, int, error) {
+	return &apiUser{}, http.StatusOK, nil
+}
+
+func apiReplaceUser(context.Context, *apiUserBodyRequest) (*apiUser, int, error) {
+	return &apiUser{}, http.StatusOK, nil
+}
+
+func Test_splitPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern, method, path string
+	}{
+		{"GET /users/{id}", http.MethodGet, "/users/{id}"},
+		{"/users", "", "/users"},
+		{"post example.com/files/{path...}", http.MethodPost, "/files/{path}"},
+		{"GET /{$}", http.MethodGet, "/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			t.Parallel()
+
+			method, path := splitPattern(tt.pattern)
+			assert.Equal(t, tt.method, method)
+			assert.Equal(t, tt.path, path)
+		})
+	}
+}
+
+func TestRegistry_Document(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	handler := Register(registry, "PATCH /users/{id}", apiUpdateUser)
+	require.NotNil(t, handler)
+	RecordRoute[*apiUserBodyRequest, *apiUser](registry, "PUT /users/{id}")
+
+	document := registry.Document(OpenAPIInfo{Title: "Users", Version: "1.0.0"})
+	assert.Equal(t, "3.1.0", document.OpenAPI)
+	require.Contains(t, document.Paths, "/users/{id}")
+
+	t.Run("parameters", func(t *testing.T) {
+		t.Parallel()
+
+		operation := document.Paths["/users/{id}"]["patch"]
+		require.NotNil(t, operation)
+		assert.Equal(t, []OpenAPIParameter{
+			{Name: "id", In: "path", Required: true, Schema: &JSONSchema{Type: "integer", Format: "int64"}},
+			{
+				Name: "fields", In: "query", Style: "form", Explode: ptr(false),
+				Schema: &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string"}},
+			},
+			{Name: "timeout", In: "query", Schema: &JSONSchema{Type: "string", Format: "duration"}},
+			{Name: "X-Tenant", In: "header", Required: true, Schema: &JSONSchema{Type: "string"}},
+			{Name: "session", In: "cookie", Schema: &JSONSchema{Type: "string"}},
+		}, operation.Parameters)
+	})
+
+	t.Run("json_body", func(t *testing.T) {
+		t.Parallel()
+
+		operation := document.Paths["/users/{id}"]["patch"]
+		require.NotNil(t, operation.RequestBody)
+		assert.Equal(t, &JSONSchema{
+			Type: "object",
+			Properties: map[string]*JSONSchema{
+				"name":  {Type: "string", MinLength: ptr(uint64(3)), MaxLength: ptr(uint64(50))},
+				"email": {Type: "string", Format: "email"},
+				"Note":  {Type: "string"},
+			},
+			Required: []string{"email"},
+		}, operation.RequestBody.Content["application/json"].Schema)
+	})
+
+	t.Run("body_field_and_components", func(t *testing.T) {
+		t.Parallel()
+
+		operation := document.Paths["/users/{id}"]["put"]
+		require.NotNil(t, operation.RequestBody)
+
+		ref := &JSONSchema{Ref: "#/components/schemas/apiUser"}
+		assert.Equal(t, ref, operation.RequestBody.Content["application/json"].Schema)
+		assert.Equal(t, ref, operation.Responses["200"].Content["application/json"].Schema)
+
+		require.NotNil(t, document.Components)
+		user := document.Components.Schemas["apiUser"]
+		require.NotNil(t, user)
+		assert.Equal(t, []string{"email"}, user.Required)
+		assert.NotContains(t, user.Properties, "Password")
+		assert.Equal(t, &JSONSchema{Type: "string", Format: "email"}, user.Properties["email"])
+		assert.Equal(t, &JSONSchema{
+			Type: "integer", Format: "int32", Minimum: ptr(18.0), Maximum: ptr(130.0),
+		}, user.Properties["age"])
+		assert.Equal(t, []any{"admin", "user"}, user.Properties["role"].Enum)
+		assert.Equal(t, &JSONSchema{
+			Type: "array", MaxItems: ptr(uint64(5)), Items: &JSONSchema{Type: "string", MinLength: ptr(uint64(2))},
+		}, user.Properties["tags"])
+		assert.Equal(t, &JSONSchema{Type: "string", Format: "date-time"}, user.Properties["created"])
+		assert.Equal(t, &JSONSchema{Ref: "#/components/schemas/apiAddress"}, user.Properties["address"])
+		assert.Equal(t, []string{"street"}, document.Components.Schemas["apiAddress"].Required)
+	})
+}
+
+func TestRegistry_formBody(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	RecordRoute[*uploadRequest, string](registry, "POST /upload")
+
+	operation := registry.Document(OpenAPIInfo{}).Paths["/upload"]["post"]
+	require.NotNil(t, operation)
+	assert.Empty(t, operation.Parameters)
+	assert.Equal(t, &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"title":      {Type: "string"},
+			"avatar":     {Type: "string", Format: "binary"},
+			"attachment": {Type: "array", Items: &JSONSchema{Type: "string", Format: "binary"}},
+		},
+	}, operation.RequestBody.Content[multipartMediaType].Schema)
+	assert.Equal(t, &JSONSchema{Type: "string"}, operation.Responses["200"].Content["text/plain"].Schema)
+}
+
+func TestRegistry_defaults(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	RecordRoute[*defaultsRequest, string](registry, "GET /regions/{region}")
+
+	operation := registry.Document(OpenAPIInfo{}).Paths["/regions/{region}"]["get"]
+	require.NotNil(t, operation)
+
+	defaults := make(map[string]any, len(operation.Parameters))
+	for _, parameter := range operation.Parameters {
+		defaults[parameter.Name] = parameter.Schema.Default
+	}
+
+	assert.Equal(t, map[string]any{
+		"page":    int64(1),
+		"limit":   uint64(20),
+		"sort":    []any{"name", "-created"},
+		"X-Token": "anonymous",
+		"region":  "us",
+		"theme":   "dark",
+		"wait":    "500ms",
+		"offset":  nil,
+	}, defaults)
+}
+
+type sinceRequest struct {
+	Since time.Time `query:"since" default:"16/10/2026"`
+}
+
+func TestRegister_options(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	config := NewConfig(WithTimeLayouts("02/01/2006"))
+
+	require.NotPanics(t, func() {
+		Register(registry, "GET /events", func(context.Context, *sinceRequest) (string, int, error) {
+			return "", http.StatusOK, nil
+		}, WithConfig(config))
+	})
+
+	operation := registry.Document(OpenAPIInfo{}).Paths["/events"]["get"]
+	require.NotNil(t, operation)
+	require.Len(t, operation.Parameters, 1)
+	assert.Equal(t, "since", operation.Parameters[0].Name)
+}
+
+func TestWithRoute(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	parser, release := CreateParser[*apiUpdateUserRequest]()
+	CreateHandler(parser, release, apiUpdateUser, WithRoute(registry, "PATCH /users/{id}"))
+	CreateSimpleHandler(apiReplaceUser, WithRoute(registry, "/users/{id}/replace"))
+
+	document := registry.Document(OpenAPIInfo{})
+	require.Contains(t, document.Paths, "/users/{id}")
+	assert.Contains(t, document.Paths["/users/{id}"], "patch")
+
+	replace := document.Paths["/users/{id}/replace"]
+	assert.Len(t, replace, len(patternMethods), "patterns without a method match every method")
+	assert.NotNil(t, replace["delete"].RequestBody)
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	handler := registry.Handler(OpenAPIInfo{Title: "Users", Version: "1.0.0"})
+
+	// routes recorded after the handler was created are included
+	Register(registry, "PUT /users/{id}", apiReplaceUser)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, "3.1.0", document["openapi"])
+	assert.Contains(t, document["paths"], "/users/{id}")
+}
+
+func Test_schemaGenerator_schema(t *testing.T) {
+	t.Parallel()
+
+	g := newSchemaGenerator()
+	tests := []struct {
+		name     string
+		t        reflect.Type
+		expected *JSONSchema
+	}{
+		{"bytes", reflect.TypeFor[[]byte](), &JSONSchema{Type: "string", Format: "byte"}},
+		{"map", reflect.TypeFor[map[string]float32](), &JSONSchema{
+			Type: "object", AdditionalProperties: &JSONSchema{Type: "number", Format: "float"},
+		}},
+		{"duration", reflect.TypeFor[time.Duration](), &JSONSchema{Type: "integer", Format: "int64"}},
+		{"any", reflect.TypeFor[any](), &JSONSchema{}},
+		{"bool_pointer", reflect.TypeFor[*bool](), &JSONSchema{Type: "boolean"}},
+		{"optional", reflect.TypeFor[Optional[[]string]](), &JSONSchema{
+			Type: "array", Items: &JSONSchema{Type: "string"},
+		}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, g.schema(tt.t), tt.name)
+	}
+}
+
+func TestRegistry_errorResponses(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	RecordRoute[*apiUpdateUserRequest, *apiUser](registry, "PATCH /users/{id}", WithMaxBodySize(1<<20))
+	RecordRoute[*defaultsRequest, string](registry, "GET /regions/{region}", WithProblemDetails(true))
+
+	document := registry.Document(OpenAPIInfo{})
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		responses := document.Paths["/users/{id}"]["patch"].Responses
+		assert.ElementsMatch(t, []string{"200", "400", "406", "413", "415"}, slices.Collect(maps.Keys(responses)))
+		assert.Equal(t, &JSONSchema{Ref: "#/components/schemas/ValidationErrorResponse"},
+			responses["400"].Content["application/json"].Schema)
+		assert.Equal(t, &JSONSchema{Type: "string"}, responses["400"].Content["text/plain"].Schema)
+		assert.Equal(t, &JSONSchema{Type: "string"}, responses["415"].Content["text/plain"].Schema)
+		assert.Contains(t, document.Components.Schemas["ValidationErrorResponse"].Properties, "errors")
+	})
+	t.Run("problem_details", func(t *testing.T) {
+		t.Parallel()
+
+		responses := document.Paths["/regions/{region}"]["get"].Responses
+		assert.ElementsMatch(t, []string{"200", "400", "406"}, slices.Collect(maps.Keys(responses)),
+			"requests without a body have no 413 and 415 responses")
+		assert.Equal(t, &JSONSchema{Ref: "#/components/schemas/ProblemDetails"},
+			responses["406"].Content[problemMediaType].Schema)
+		assert.Contains(t, document.Components.Schemas["ProblemDetails"].Properties, "status")
+	})
+}
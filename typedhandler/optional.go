@@ -0,0 +1,183 @@
+package typedhandler
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type (
+	// Optional is a request value that tells an absent value from a zero value, without a pointer:
+	//
+	//	type SearchRequest struct {
+	//		MinAge typedhandler.Optional[int] `query:"min_age"`
+	//	}
+	//
+	//	if minAge, ok := request.MinAge.Get(); ok {
+	//		// filter by age
+	//	}
+	//
+	// The validation rules of an Optional field apply to its value. An unset value is validated like a nil
+	// pointer, so use "omitempty" for rules that apply only to values sent in the request
+	Optional[T any] struct {
+		value T
+		set   bool
+	}
+
+	// optionalField is implemented by *Optional[T], to set the values converted from the request
+	optionalField interface {
+		valueType() reflect.Type
+		convert(convert func(value reflect.Value) error) error
+	}
+
+	// optionalValidation is an Optional type registered into a validator
+	optionalValidation struct {
+		validator *validator.Validate
+		typ       reflect.Type
+	}
+)
+
+var (
+	optionalFieldType = reflect.TypeFor[optionalField]()
+
+	validatorOptionalTypes sync.Map // Optional types registered into the validators, by optionalValidation
+)
+
+// Some returns an Optional set to the value
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// IsSet returns true if the value was sent in the request (or set with Some)
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsZero returns true if the value is not set, so fields tagged with json "omitzero" are omitted
+func (o Optional[T]) IsZero() bool {
+	return !o.set
+}
+
+// Get returns the value and true if it is set, or the zero value and false
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// OrElse returns the value if it is set, or the fallback value
+func (o Optional[T]) OrElse(fallback T) T {
+	if !o.set {
+		return fallback
+	}
+
+	return o.value
+}
+
+// MarshalJSON encodes the value, or null if it is not set
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.set {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON sets the value from JSON. A null value leaves it unset
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*o = Optional[T]{}
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	o.value, o.set = value, true
+
+	return nil
+}
+
+// valueType returns the type of the value
+func (o *Optional[T]) valueType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// convert sets the value converted by the func, only when the conversion succeeds
+func (o *Optional[T]) convert(convert func(value reflect.Value) error) error {
+	var value T
+	if err := convert(reflect.ValueOf(&value).Elem()); err != nil {
+		return err
+	}
+
+	o.value, o.set = value, true
+
+	return nil
+}
+
+// validationValue returns the value to be validated like a pointer: nil if it is not set,
+// or a pointer to a copy of the value, so "omitempty" skips only the unset values
+func (o Optional[T]) validationValue() any {
+	if !o.set {
+		return (*T)(nil)
+	}
+
+	return &o.value
+}
+
+// optionalType returns the type of the value held by a pointer or an Optional type, or nil for other types
+func optionalType(t reflect.Type) reflect.Type {
+	switch {
+	case t.Kind() == reflect.Pointer:
+		return t.Elem()
+	case t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(optionalFieldType):
+		return reflect.New(t).Interface().(optionalField).valueType()
+	default:
+		return nil
+	}
+}
+
+// valueType returns the type of the values converted into a field of type t:
+// the type held by pointer and Optional fields, or t itself
+func valueType(t reflect.Type) reflect.Type {
+	for value := optionalType(t); value != nil; value = optionalType(t) {
+		t = value
+	}
+
+	return t
+}
+
+// convertOptional converts into a new value of the type held by the pointer or Optional field,
+// and sets the field only when the conversion succeeds. Absent values leave the field nil or unset
+func convertOptional(field reflect.Value, convert func(value reflect.Value) error) error {
+	if field.Kind() != reflect.Pointer {
+		return field.Addr().Interface().(optionalField).convert(convert)
+	}
+
+	value := reflect.New(field.Type().Elem())
+	if err := convert(value.Elem()); err != nil {
+		return err
+	}
+
+	field.Set(value)
+
+	return nil
+}
+
+// registerOptionalValidation makes the validator v validate the value of the Optional type t,
+// instead of the Optional struct. Validators that are not a *validator.Validate (nil) validate the Optional struct
+func registerOptionalValidation(v *validator.Validate, t reflect.Type) {
+	if v == nil || t.Kind() != reflect.Struct || !reflect.PointerTo(t).Implements(optionalFieldType) {
+		return
+	}
+
+	if _, registered := validatorOptionalTypes.LoadOrStore(optionalValidation{v, t}, true); registered {
+		return
+	}
+
+	v.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		return field.Interface().(interface{ validationValue() any }).validationValue()
+	}, reflect.New(t).Elem().Interface())
+}
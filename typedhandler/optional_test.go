@@ -0,0 +1,146 @@
+package typedhandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type optionalRequest struct {
+	MinAge *int               `query:"min_age"`
+	Name   *string            `query:"name"`
+	Since  *time.Time         `query:"since"`
+	IDs    *[]int             `query:"ids,explode=false"`
+	Tenant *string            `header:"X-Tenant"`
+	Active Optional[bool]     `query:"active"`
+	Tags   Optional[[]string] `query:"tag"`
+	Region Optional[string]   `header:"X-Region"`
+	Limit  Optional[int]      `query:"limit" default:"10" validate:"omitempty,max=100"`
+	Page   Optional[int]      `query:"page"               validate:"omitempty,min=1"`
+}
+
+func TestOptional(t *testing.T) {
+	t.Parallel()
+
+	var unset Optional[int]
+	assert.False(t, unset.IsSet())
+	assert.True(t, unset.IsZero())
+	assert.Equal(t, 5, unset.OrElse(5))
+
+	value, ok := unset.Get()
+	assert.False(t, ok)
+	assert.Zero(t, value)
+
+	zero := Some(0)
+	assert.True(t, zero.IsSet())
+	assert.Equal(t, 0, zero.OrElse(5))
+
+	value, ok = zero.Get()
+	assert.True(t, ok)
+	assert.Zero(t, value)
+}
+
+func TestOptional_JSON(t *testing.T) {
+	t.Parallel()
+
+	type body struct {
+		Name  Optional[string] `json:"name"`
+		Age   Optional[int]    `json:"age"`
+		Email Optional[string] `json:"email,omitzero"`
+	}
+
+	var decoded body
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"","age":null}`), &decoded))
+	assert.Equal(t, body{Name: Some("")}, decoded)
+
+	encoded, err := json.Marshal(body{Age: Some(42)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":null,"age":42}`, string(encoded))
+
+	require.Error(t, json.Unmarshal([]byte(`{"age":"x"}`), &decoded))
+}
+
+func TestSchemaHelper_optionalFields(t *testing.T) {
+	t.Parallel()
+
+	parser, release := CreateParser[*optionalRequest]()
+
+	t.Run("absent", func(t *testing.T) {
+		t.Parallel()
+
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/", nil))
+		defer release(instance)
+
+		require.NoError(t, err)
+		assert.Equal(t, optionalRequest{Limit: Some(10)}, *instance)
+	})
+	t.Run("present", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet,
+			"/?min_age=0&name=&since=2025-01-02T00:00:00Z&ids=1,2&active=false&tag=a&tag=b&limit=20&page=3", nil)
+		r.Header.Set("X-Tenant", "acme")
+		r.Header.Set("X-Region", "")
+
+		instance, err := parser(r)
+		defer release(instance)
+
+		require.NoError(t, err)
+		assert.Equal(t, optionalRequest{
+			MinAge: ptr(0),
+			Name:   ptr(""),
+			Since:  ptr(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)),
+			IDs:    &[]int{1, 2},
+			Tenant: ptr("acme"),
+			Active: Some(false),
+			Tags:   Some([]string{"a", "b"}),
+			Region: Some(""),
+			Limit:  Some(20),
+			Page:   Some(3),
+		}, *instance)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/?min_age=x", nil))
+		defer release(instance)
+
+		var parseError ParseError
+		require.ErrorAs(t, err, &parseError)
+		assert.Equal(t, "min_age", parseError.Param)
+		assert.Nil(t, instance.MinAge)
+	})
+	t.Run("validation", func(t *testing.T) {
+		t.Parallel()
+
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/?limit=200&page=0", nil))
+		defer release(instance)
+
+		var validationErrors validator.ValidationErrors
+		require.ErrorAs(t, err, &validationErrors)
+
+		rules := make(map[string]string, len(validationErrors))
+		for _, fieldError := range validationErrors {
+			rules[validationFieldName(fieldError)] = fieldError.Tag()
+		}
+
+		assert.Equal(t, map[string]string{"limit": "max", "page": "min"}, rules)
+	})
+}
+
+func Test_valueType(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, reflect.TypeFor[int](), valueType(reflect.TypeFor[*int]()))
+	assert.Equal(t, reflect.TypeFor[[]string](), valueType(reflect.TypeFor[Optional[[]string]]()))
+	assert.Equal(t, reflect.TypeFor[time.Time](), valueType(reflect.TypeFor[*Optional[time.Time]]()))
+	assert.Equal(t, reflect.TypeFor[string](), valueType(reflect.TypeFor[string]()))
+	assert.False(t, isNestedStruct(reflect.TypeFor[Optional[int]]()))
+}
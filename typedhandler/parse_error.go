@@ -0,0 +1,231 @@
+package typedhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mailru/easyjson/jlexer"
+)
+
+type (
+	// ParseError is returned when a request value can't be converted into the type of its field,
+	// or when the request body can't be decoded
+	ParseError struct {
+		Source string // source of the value: "path", "query", "header", "cookie", "form" or "body"
+		Param  string // name of the value in the request (or JSON path of the body value)
+		Field  string // name of the field in the struct
+		Type   string // type of the field
+		Value  string // raw value (comma-separated for multiple values)
+		Offset int64  // byte offset of the error in the body, 0 when the codec does not report it
+		Err    error  // conversion or decoding error
+
+		status int // status set by WithPathErrorStatus, 0 for the default status of the source
+	}
+
+	// BodyTooLargeError is returned when the request body is larger than the limit set by WithMaxBodySize
+	BodyTooLargeError struct {
+		Limit int64 // max size of the body, in bytes
+	}
+
+	// RequestErrors holds every error of a request parsed with AggregateParseErrors:
+	// the ParseErrors of all the sources and the validation errors of the fields that were parsed.
+	// It also combines the errors of the validation stages (tags, Validatable and ContextValidatable)
+	RequestErrors []error
+)
+
+// Sources of the request values
+const (
+	SourcePath   = "path"
+	SourceQuery  = "query"
+	SourceHeader = "header"
+	SourceCookie = "cookie"
+	SourceForm   = "form"
+	SourceBody   = "body"
+)
+
+// PathErrorStatus is the status of ParseErrors of path values - http.StatusBadRequest by default.
+// Use http.StatusNotFound to respond to invalid path values like to unknown resources.
+// WithPathErrorStatus sets the status of a parser
+var PathErrorStatus = http.StatusBadRequest
+
+// AggregateParseErrors parses every source of the request and collects all the errors into RequestErrors,
+// instead of stopping at the first one - normally disabled.
+// The validation runs on the fields that were parsed. WithAggregateErrors sets the mode of a parser
+var AggregateParseErrors = false
+
+func (e ParseError) Error() string {
+	sb := strings.Builder{}
+	sb.WriteString("invalid " + e.Source)
+
+	if e.Param != "" {
+		_, _ = fmt.Fprintf(&sb, " value %q", e.Param)
+	}
+
+	if e.Source == SourceBody {
+		if e.Offset > 0 {
+			_, _ = fmt.Fprintf(&sb, " at offset %d", e.Offset)
+		}
+	} else if e.Type != "" {
+		_, _ = fmt.Fprintf(&sb, ": cannot convert %q to %s", e.Value, e.Type)
+	}
+
+	if e.Err != nil {
+		sb.WriteString(": " + e.Err.Error())
+	}
+
+	return sb.String()
+}
+
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Status returns http.StatusBadRequest, or the status set by WithPathErrorStatus or PathErrorStatus
+// for path values
+func (e ParseError) Status() int {
+	if e.status != 0 {
+		return e.status
+	}
+
+	if e.Source == SourcePath {
+		return PathErrorStatus
+	}
+
+	return http.StatusBadRequest
+}
+
+// ProblemDetails adds the source and the name of the value to the problem details
+func (e ParseError) ProblemDetails(problem *ProblemDetails) {
+	if problem.Extensions == nil {
+		problem.Extensions = make(map[string]any)
+	}
+
+	problem.Extensions["source"] = e.Source
+	if e.Param != "" {
+		problem.Extensions["param"] = e.Param
+	}
+
+	if e.Source == SourceBody && e.Offset > 0 {
+		problem.Extensions["offset"] = e.Offset
+	}
+}
+
+// newBodyParseError wraps the errors of bodies larger than the limit of the Config into a BodyTooLargeError,
+// and the decoding errors of the body (syntax and type errors, truncated or empty bodies, ...) into a ParseError,
+// with the name of the value and the offset of the JSON errors
+// HttpErrors, like UnsupportedMediaTypeError and the errors of custom codecs, are returned as they are
+func newBodyParseError(err error) error {
+	if err == nil {
+		return nil // before the targets of errors.As, that escape to the heap
+	}
+
+	var (
+		syntaxError *json.SyntaxError
+		typeError   *json.UnmarshalTypeError
+		lexerError  *jlexer.LexerError
+		bytesError  *http.MaxBytesError
+		httpError   HttpError
+	)
+
+	switch {
+	case errors.As(err, &bytesError):
+		return BodyTooLargeError{Limit: bytesError.Limit}
+	case errors.As(err, &httpError):
+		return err
+	case errors.As(err, &typeError):
+		parseError := ParseError{
+			Source: SourceBody,
+			Param:  typeError.Field,
+			Value:  typeError.Value,
+			Offset: typeError.Offset,
+			Err:    err,
+		}
+		if typeError.Type != nil {
+			parseError.Type = typeError.Type.String()
+		}
+
+		return parseError
+	case errors.As(err, &syntaxError):
+		return ParseError{Source: SourceBody, Offset: syntaxError.Offset, Err: err}
+	case errors.As(err, &lexerError):
+		return ParseError{Source: SourceBody, Value: lexerError.Data, Offset: int64(lexerError.Offset), Err: err}
+	default:
+		return ParseError{Source: SourceBody, Err: err}
+	}
+}
+
+func (e BodyTooLargeError) Error() string {
+	return fmt.Sprintf("request body too large: the limit is %d bytes", e.Limit)
+}
+
+func (e BodyTooLargeError) Status() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+func (e RequestErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (e RequestErrors) Unwrap() []error {
+	return e
+}
+
+// Status returns the highest status of the errors: http.StatusBadRequest for parse and validation errors,
+// the status of other HttpErrors, and http.StatusInternalServerError for the remaining errors
+func (e RequestErrors) Status() int {
+	status := http.StatusBadRequest
+
+	for _, err := range e {
+		var (
+			httpError     HttpError
+			validateError validator.ValidationErrors
+		)
+
+		switch {
+		case errors.As(err, &validateError):
+		case errors.As(err, &httpError):
+			status = max(status, httpError.Status())
+		default:
+			status = http.StatusInternalServerError
+		}
+	}
+
+	return status
+}
+
+// joinParseError adds err to the errors of a source of the request, and returns true if the parsing
+// of the source must stop: on the first error, unless all the errors are aggregated
+func joinParseError(errs *error, err error, aggregate bool) (stop bool) {
+	switch {
+	case err == nil:
+		return false
+	case *errs == nil:
+		*errs = err
+	default:
+		*errs = errors.Join(append(appendErrors(nil, *errs), err)...) // flat, to be listed by appendErrors
+	}
+
+	return !aggregate
+}
+
+// appendErrors appends err to the errors, flattening the errors joined by errors.Join
+func appendErrors(errs []error, err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return append(errs, joined.Unwrap()...)
+	}
+
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
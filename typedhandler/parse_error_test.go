@@ -0,0 +1,268 @@
+package typedhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/guionardo/typedhandler/examples/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	parseErrorRequest struct {
+		ID   int    `path:"id"`
+		Page int    `query:"page"`
+		IDs  [2]int `query:"ids,explode=false"`
+	}
+	parseErrorBodyRequest struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+)
+
+func TestParseError(t *testing.T) {
+	t.Parallel()
+
+	parser, release := CreateParser[*parseErrorRequest]()
+	parse := func(target string) error {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.SetPathValue("id", "12")
+
+		instance, err := parser(r)
+		release(instance)
+
+		return err
+	}
+
+	t.Run("query", func(t *testing.T) {
+		t.Parallel()
+
+		err := parse("/?page=abc")
+
+		var parseError ParseError
+		require.ErrorAs(t, err, &parseError)
+		assert.Equal(t, SourceQuery, parseError.Source)
+		assert.Equal(t, "page", parseError.Param)
+		assert.Equal(t, "Page", parseError.Field)
+		assert.Equal(t, "int", parseError.Type)
+		assert.Equal(t, "abc", parseError.Value)
+		assert.Equal(t, http.StatusBadRequest, parseError.Status())
+
+		var numError *strconv.NumError
+		require.ErrorAs(t, err, &numError)
+		assert.True(t, strings.HasPrefix(err.Error(), `invalid query value "page": cannot convert "abc" to int: `))
+	})
+	t.Run("too_many_values", func(t *testing.T) {
+		t.Parallel()
+
+		err := parse("/?page=1&ids=1,2,3")
+
+		var parseError ParseError
+		require.ErrorAs(t, err, &parseError)
+		assert.Equal(t, "1,2,3", parseError.Value)
+		assert.Equal(t, "[2]int", parseError.Type)
+		require.ErrorAs(t, err, &TooManyValuesError{})
+	})
+	t.Run("path", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/?page=1", nil)
+		r.SetPathValue("id", "x")
+
+		instance, err := parser(r)
+		release(instance)
+
+		var parseError ParseError
+		require.ErrorAs(t, err, &parseError)
+		assert.Equal(t, SourcePath, parseError.Source)
+		assert.Equal(t, "id", parseError.Param)
+	})
+}
+
+func TestParseError_body(t *testing.T) {
+	t.Parallel()
+
+	parser, release := CreateParser[*parseErrorBodyRequest]()
+	easyjsonParser, easyjsonRelease := CreateParser[*sample.Request]()
+
+	parse := func(r *http.Request) error {
+		instance, err := parser(r)
+		release(instance)
+
+		return err
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		parse       func(r *http.Request) error
+		param       string
+		offset      int64
+		wrapped     error
+	}{
+		{"syntax", jsonMediaType, `{"name": "john",}`, parse, "", 17, nil},
+		{"type", jsonMediaType, `{"name": "john", "age": "ten"}`, parse, "age", 29, nil},
+		{"easyjson", jsonMediaType, `{"name": "john", "age": "ten"}`, func(r *http.Request) error {
+			instance, err := easyjsonParser(r)
+			easyjsonRelease(instance)
+
+			return err
+		}, "", 29, nil},
+		{"truncated", jsonMediaType, `{"name":`, parse, "", 0, io.ErrUnexpectedEOF},
+		{"empty", jsonMediaType, ``, parse, "", 0, io.EOF},
+		{"xml_syntax", xmlMediaType, `<request><Name>john</request>`, parse, "", 0, nil},
+		{"xml_type", xmlMediaType, `<request><Age>ten</Age></request>`, parse, "", 0, strconv.ErrSyntax},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			err := tt.parse(r)
+
+			var parseError ParseError
+			require.ErrorAs(t, err, &parseError)
+			assert.Equal(t, SourceBody, parseError.Source)
+			assert.Equal(t, tt.param, parseError.Param)
+			assert.Equal(t, tt.offset, parseError.Offset)
+			assert.Equal(t, http.StatusBadRequest, parseError.Status())
+			assert.Contains(t, err.Error(), "invalid body")
+
+			if tt.wrapped != nil {
+				require.ErrorIs(t, err, tt.wrapped)
+			}
+		})
+	}
+	t.Run("handler", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		CreateSimpleHandler(func(context.Context, *parseErrorBodyRequest) (string, int, error) {
+			return "", http.StatusOK, nil
+		})(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":`)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid body: unexpected EOF", w.Body.String())
+	})
+	t.Run("unsupported_media_type", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`name: john`))
+		r.Header.Set("Content-Type", "application/yaml")
+
+		var unsupported UnsupportedMediaTypeError
+		require.ErrorAs(t, parse(r), &unsupported)
+	})
+}
+
+func TestPathErrorStatus(t *testing.T) { //nolint:paralleltest // changes PathErrorStatus
+	PathErrorStatus = http.StatusNotFound
+	t.Cleanup(func() { PathErrorStatus = http.StatusBadRequest })
+
+	handler := CreateSimpleHandler(func(context.Context, *parseErrorRequest) (string, int, error) {
+		return "", http.StatusOK, nil
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/?page=1", nil)
+	r.SetPathValue("id", "x")
+
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), `invalid path value "id"`)
+
+	assert.Equal(t, http.StatusBadRequest, ParseError{Source: SourceQuery, Err: errors.New("x")}.Status())
+}
+
+type aggregateRequest struct {
+	Page   int    `query:"page"   validate:"min=1"`
+	Limit  int    `query:"limit"  validate:"max=100"`
+	Offset int    `query:"offset" validate:"min=0"`
+	Name   string `query:"name"   validate:"required"`
+	Age    int    `json:"age"     validate:"gte=18"`
+}
+
+func TestAggregateParseErrors(t *testing.T) { //nolint:paralleltest // changes AggregateParseErrors
+	AggregateParseErrors = true
+	t.Cleanup(func() { AggregateParseErrors = false })
+
+	parser, release := CreateParser[*aggregateRequest]()
+	handler := CreateHandler(parser, release, func(context.Context, *aggregateRequest) (string, int, error) {
+		return "ok", http.StatusOK, nil
+	})
+
+	t.Run("parser", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/?page=a&limit=b&offset=c", strings.NewReader(`{"age": "x"}`))
+
+		instance, err := parser(r)
+		release(instance)
+
+		var requestErrors RequestErrors
+		require.ErrorAs(t, err, &requestErrors)
+		require.Len(t, requestErrors, 5) // body, 3 query values and the validation of name
+
+		var parseErrors []string
+
+		for _, requestError := range requestErrors[:4] {
+			var parseError ParseError
+			require.ErrorAs(t, requestError, &parseError)
+			parseErrors = append(parseErrors, parseError.Source+":"+parseError.Param)
+		}
+
+		assert.Equal(t, []string{"body:age", "query:page", "query:limit", "query:offset"}, parseErrors)
+
+		// only the fields that were parsed are validated
+		var validateErrors validator.ValidationErrors
+		require.ErrorAs(t, requestErrors[4], &validateErrors)
+		require.Len(t, validateErrors, 1)
+		assert.Equal(t, "name", validateErrors[0].Field())
+		assert.Equal(t, http.StatusBadRequest, requestErrors.Status())
+	})
+	t.Run("handler", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/?page=0&limit=b&name=john", strings.NewReader(`{"age":20}`)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response ValidationErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Errors, 2) // the absent offset is not converted
+		assert.Equal(t, "limit", response.Errors[0].Field)
+		assert.Equal(t, SourceQuery, response.Errors[0].Source)
+		assert.Equal(t, "parse", response.Errors[0].Rule)
+		assert.Equal(t, "page", response.Errors[1].Field)
+		assert.Equal(t, "min", response.Errors[1].Rule)
+	})
+	t.Run("success", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/?page=1&limit=2&offset=0&name=john",
+			strings.NewReader(`{"age":20}`)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("post_parse", func(t *testing.T) {
+		parser, release := CreateParser[*hooksRequest]()
+
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/?email=john", nil))
+		release(instance)
+
+		var requestErrors RequestErrors
+		require.ErrorAs(t, err, &requestErrors)
+		require.Len(t, requestErrors, 2) // the PostParse error and the validation of the email
+		require.EqualError(t, requestErrors[0], "email without domain")
+		assert.Equal(t, http.StatusUnprocessableEntity, requestErrors.Status())
+	})
+}
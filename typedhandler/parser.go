@@ -1,8 +1,10 @@
 package typedhandler
 
 import (
+	"errors"
 	"net/http"
 	"reflect"
+	"strings"
 )
 
 type (
@@ -13,37 +15,123 @@ type (
 	}
 )
 
-// CreateParser creates a ParseRequestFunc and a doneFunc for request schema RIn
+// CreateParser creates a ParseRequestFunc and a ReleaseFunc for request schema RIn
 // RIn must be a pointer type
-// The doneFunc must be called when the parser is no longer needed to release resources
-func CreateParser[RIn RequestSchema]() (parserFunc ParseRequestFunc[RIn], doneFunc func()) {
-	schemaHelper := GetSchemaHelper[RIn]()
+// Each call to the parser acquires a fresh instance (from the pool, when enabled).
+// The releaseFunc must be called with that instance once the request is handled,
+// so it can be reset and returned to the pool. CreateHandler does it for you.
+// The PreParseable and PostParseable hooks of RIn are called on that instance, before binding the request values
+// and before validating it.
+// The options (or a Config, with WithConfig) replace the package-level settings for this parser
+func CreateParser[RIn RequestSchema](options ...Option) (parserFunc ParseRequestFunc[RIn],
+	releaseFunc ReleaseFunc[RIn],
+) {
+	config := NewConfig(options...)
 
-	instance := schemaHelper.GetInstance()
+	return newParser(config, getSchemaHelper[RIn](config))
+}
 
+// newParser creates the parser and the release func of the schema helper, with the settings of the config
+func newParser[RIn RequestSchema](config *Config, schemaHelper *SchemaHelper[RIn]) (ParseRequestFunc[RIn],
+	ReleaseFunc[RIn],
+) {
 	return func(r *http.Request) (RIn, error) {
-			ptrValue := reflect.ValueOf(instance)
-			structValue := ptrValue.Elem()
+		config.limitBody(r)
 
-			var err error
-			if err = schemaHelper.parseRequestBody(r, instance); err == nil {
-				err = schemaHelper.parseRequestHeaders(r, structValue)
+		instance := schemaHelper.GetInstance()
+		if schemaHelper.preParseFunc != nil {
+			if err := schemaHelper.preParseFunc(r, instance); err != nil {
+				return instance, err
 			}
+		}
 
-			if err == nil {
-				err = schemaHelper.parseRequestPath(r, structValue)
-			}
+		structValue := reflect.ValueOf(instance).Elem()
 
-			if err == nil {
-				err = schemaHelper.parseRequestQuery(r, structValue)
-			}
+		if config.aggregateParseErrors() {
+			return instance, schemaHelper.parseAll(r, instance, structValue)
+		}
 
-			if err == nil {
-				err = schemaHelper.validateFunc(instance)
-			}
+		return instance, schemaHelper.parse(r, instance, structValue)
+	}, schemaHelper.PutInstance
+}
+
+// parse parses the sources of the request until the first error, then calls the PostParseable hook
+// and validates the instance
+func (sh *SchemaHelper[RIn]) parse(r *http.Request, instance RIn, structValue reflect.Value) error {
+	var err error
+	if err = sh.parseRequestBody(r, instance); err == nil {
+		err = sh.parseRequestHeaders(r, structValue)
+	}
+
+	if err == nil {
+		err = sh.parseRequestForm(r, structValue)
+	}
+
+	if err == nil {
+		err = sh.parseRequestCookies(r, structValue)
+	}
+
+	if err == nil {
+		err = sh.parseRequestPath(r, structValue)
+	}
+
+	if err == nil {
+		err = sh.parseRequestQuery(r, structValue)
+	}
+
+	if err == nil && sh.postParseFunc != nil {
+		err = sh.postParseFunc(r, instance)
+	}
+
+	if err == nil {
+		err = sh.validateFunc(r.Context(), instance)
+	}
+
+	return err
+}
+
+// parseAll parses every source of the request, collecting all the errors into RequestErrors
+// The PostParseable hook and the validation run on the fields that were parsed: fields with ParseErrors,
+// and the body fields when the body can't be decoded, are not validated
+func (sh *SchemaHelper[RIn]) parseAll(r *http.Request, instance RIn, structValue reflect.Value) error {
+	var (
+		errs   []error
+		except []string
+	)
+
+	if err := sh.parseRequestBody(r, instance); err != nil {
+		errs = append(errs, err)
+		except = append(except, sh.bodyFields...)
+	}
+
+	errs = appendErrors(errs, sh.parseRequestHeaders(r, structValue))
+	errs = appendErrors(errs, sh.parseRequestForm(r, structValue))
+	errs = appendErrors(errs, sh.parseRequestCookies(r, structValue))
+	errs = appendErrors(errs, sh.parseRequestPath(r, structValue))
+	errs = appendErrors(errs, sh.parseRequestQuery(r, structValue))
 
-			return instance, err
-		}, func() {
-			schemaHelper.PutInstance(instance)
+	if sh.postParseFunc != nil {
+		errs = appendErrors(errs, sh.postParseFunc(r, instance))
+	}
+
+	for _, err := range errs {
+		var parseError ParseError
+		if errors.As(err, &parseError) && parseError.Field != "" {
+			except = append(except, parseError.Field)
 		}
+	}
+
+	errs = appendErrors(errs, sh.validateFunc(r.Context(), instance, except...))
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return RequestErrors(errs)
+}
+
+// mediaType returns the media type from the Content-Type header of the request, without parameters.
+func mediaType(r *http.Request) string {
+	contentType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+
+	return strings.ToLower(strings.TrimSpace(contentType))
 }
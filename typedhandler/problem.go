@@ -0,0 +1,171 @@
+package typedhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+type (
+	// ProblemDetails is an RFC 9457 problem details object, written as application/problem+json
+	// Extensions are written as members of the object, besides the standard ones
+	ProblemDetails struct {
+		Type       string              `json:"type"`
+		Title      string              `json:"title"`
+		Status     int                 `json:"status"`
+		Detail     string              `json:"detail,omitempty"`
+		Instance   string              `json:"instance,omitempty"`
+		Errors     []ProblemFieldError `json:"errors,omitempty"` // validation errors
+		Extensions map[string]any      `json:"-"`
+	}
+
+	// ProblemFieldError describes the validation error of a field, in the "errors" extension
+	ProblemFieldError struct {
+		Field   string `json:"field"`
+		Tag     string `json:"tag"`
+		Message string `json:"message"`
+	}
+
+	// ProblemDetailer represents an error that fills its own members of the problem details,
+	// usually extension members
+	ProblemDetailer interface {
+		error
+		ProblemDetails(problem *ProblemDetails)
+	}
+)
+
+const problemMediaType = "application/problem+json"
+
+// ProblemDetailsEnabled writes every error response (parse, PreParse, validation and service errors)
+// as RFC 9457 problem details - normally disabled. WithProblemDetails sets the format of a handler
+var ProblemDetailsEnabled = false
+
+// newProblemDetails creates the problem details of the error of the request
+func newProblemDetails(r *http.Request, err error) *ProblemDetails {
+	var (
+		httpError     HttpError
+		validateError validator.ValidationErrors
+		detailer      ProblemDetailer
+	)
+
+	status := http.StatusInternalServerError
+	if errors.As(err, &httpError) {
+		status = httpError.Status()
+	}
+
+	problem := &ProblemDetails{
+		Type:   "about:blank",
+		Status: status,
+		Detail: err.Error(),
+	}
+	if r != nil {
+		problem.Instance = r.URL.Path
+	}
+
+	var requestErrors RequestErrors
+
+	switch {
+	case errors.As(err, &requestErrors):
+		problem.Detail = "the request has invalid values"
+		trans := requestTranslator(r)
+
+		for _, requestError := range requestErrors {
+			problem.Errors = append(problem.Errors, problemFieldErrors(requestError, trans)...)
+		}
+	case errors.As(err, &validateError):
+		problem.Status = http.StatusBadRequest
+		problem.Detail = "the request has invalid fields"
+		problem.Errors = problemFieldErrors(validateError, requestTranslator(r))
+	}
+
+	problem.Title = http.StatusText(problem.Status)
+
+	if requestErrors == nil && errors.As(err, &detailer) {
+		detailer.ProblemDetails(problem)
+	}
+
+	return problem
+}
+
+// problemFieldErrors returns the items of the "errors" extension for a validation or parse error
+// Parse errors have the "parse" tag
+func problemFieldErrors(err error, trans ut.Translator) []ProblemFieldError {
+	var (
+		validateError validator.ValidationErrors
+		parseError    ParseError
+	)
+
+	switch {
+	case errors.As(err, &validateError):
+		fieldErrors := make([]ProblemFieldError, len(validateError))
+		for i, fieldError := range validateError {
+			fieldErrors[i] = ProblemFieldError{
+				Field:   validationFieldName(fieldError),
+				Tag:     fieldError.Tag(),
+				Message: translateMessage(fieldError, trans),
+			}
+		}
+
+		return fieldErrors
+	case errors.As(err, &parseError):
+		return []ProblemFieldError{{Field: parseError.Param, Tag: parseRule, Message: parseError.Error()}}
+	default:
+		return []ProblemFieldError{{Message: err.Error()}}
+	}
+}
+
+// MarshalJSON encodes the standard members and the extension members into a single object
+// The standard members take precedence over extensions with the same name
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	type problemDetails ProblemDetails // without the MarshalJSON method
+
+	standard, err := json.Marshal((*problemDetails)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return standard, err
+	}
+
+	members := make(map[string]any, len(p.Extensions))
+	for name, value := range p.Extensions {
+		members[name] = value
+	}
+
+	var standardMembers map[string]json.RawMessage
+	if err := json.Unmarshal(standard, &standardMembers); err != nil {
+		return nil, err
+	}
+
+	for name, value := range standardMembers {
+		members[name] = value
+	}
+
+	return json.Marshal(members)
+}
+
+// WriteProblemDetails writes the error of the request as problem details, whatever ProblemDetailsEnabled is.
+// Custom ErrorRenderers can use it for some of the errors:
+//
+//	typedhandler.WithErrorRenderer(func(w http.ResponseWriter, r *http.Request, err error) {
+//		if errors.Is(err, ErrLegacy) {
+//			legacyError(w, err)
+//			return
+//		}
+//		typedhandler.WriteProblemDetails(w, r, err)
+//	})
+func WriteProblemDetails(w http.ResponseWriter, r *http.Request, err error) {
+	problem := newProblemDetails(r, err)
+
+	body, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(marshalErr.Error()))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", problemMediaType)
+	w.WriteHeader(problem.Status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,146 @@
+package typedhandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	outOfCreditError struct {
+		Balance int
+	}
+	problemRequest struct {
+		Name string `query:"name" validate:"required"`
+	}
+)
+
+func (e outOfCreditError) Error() string {
+	return fmt.Sprintf("your current balance is %d", e.Balance)
+}
+
+func (e outOfCreditError) Status() int {
+	return http.StatusForbidden
+}
+
+func (e outOfCreditError) ProblemDetails(problem *ProblemDetails) {
+	problem.Type = "https://example.com/probs/out-of-credit"
+	problem.Title = "You do not have enough credit."
+	problem.Extensions = map[string]any{"balance": e.Balance, "status": 0}
+}
+
+func Test_newProblemDetails(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/account/12345?x=1", nil)
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		problem := newProblemDetails(r, errors.New("database is down"))
+		assert.Equal(t, &ProblemDetails{
+			Type:     "about:blank",
+			Title:    "Internal Server Error",
+			Status:   http.StatusInternalServerError,
+			Detail:   "database is down",
+			Instance: "/account/12345",
+		}, problem)
+	})
+	t.Run("http_error", func(t *testing.T) {
+		t.Parallel()
+
+		problem := newProblemDetails(r, fmt.Errorf("parse: %w", httpError{StatusCode: http.StatusNotFound, Message: "x"}))
+		assert.Equal(t, http.StatusNotFound, problem.Status)
+		assert.Equal(t, "Not Found", problem.Title)
+		assert.Equal(t, "parse: x", problem.Detail)
+	})
+	t.Run("validation_error", func(t *testing.T) {
+		t.Parallel()
+
+		err := validator.New().Struct(problemRequest{})
+		problem := newProblemDetails(r, err)
+		assert.Equal(t, http.StatusBadRequest, problem.Status)
+		require.Len(t, problem.Errors, 1)
+		assert.Equal(t, "Name", problem.Errors[0].Field)
+		assert.Equal(t, "required", problem.Errors[0].Tag)
+		assert.Contains(t, problem.Errors[0].Message, "'required' tag")
+	})
+	t.Run("request_errors", func(t *testing.T) {
+		t.Parallel()
+
+		err := RequestErrors{
+			ParseError{Source: SourceQuery, Param: "page", Type: "int", Value: "a", Err: errors.New("invalid syntax")},
+			validator.New().Struct(problemRequest{}),
+		}
+		problem := newProblemDetails(r, err)
+		assert.Equal(t, http.StatusBadRequest, problem.Status)
+		assert.Nil(t, problem.Extensions)
+		assert.Equal(t, []ProblemFieldError{
+			{Field: "page", Tag: "parse", Message: `invalid query value "page": cannot convert "a" to int: invalid syntax`},
+			{Field: "Name", Tag: "required", Message: "Field validation for 'Name' failed on the 'required' tag"},
+		}, problem.Errors)
+	})
+	t.Run("problem_detailer", func(t *testing.T) {
+		t.Parallel()
+
+		body, err := newProblemDetails(r, outOfCreditError{Balance: 30}).MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"type": "https://example.com/probs/out-of-credit",
+			"title": "You do not have enough credit.",
+			"status": 403,
+			"detail": "your current balance is 30",
+			"instance": "/account/12345",
+			"balance": 30
+		}`, string(body))
+	})
+}
+
+func TestProblemDetailsEnabled(t *testing.T) { //nolint:paralleltest // changes ProblemDetailsEnabled
+	ProblemDetailsEnabled = true
+	t.Cleanup(func() { ProblemDetailsEnabled = false })
+
+	handler := CreateSimpleHandler(func(_ context.Context, request *problemRequest) (string, int, error) {
+		if request.Name == "broke" {
+			return "", 0, outOfCreditError{Balance: 10}
+		}
+
+		return "hello " + request.Name, http.StatusOK, nil
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+		body   string
+	}{
+		{"validation_error", "/hello", http.StatusBadRequest, `"errors":[{"field":"name","tag":"required"`},
+		{"service_error", "/hello?name=broke", http.StatusForbidden, `"balance":10`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, problemMediaType, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Body.String(), tt.body)
+			assert.Contains(t, w.Body.String(), `"instance":"/hello"`)
+		})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/hello?name=john", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"hello john"`, w.Body.String())
+	})
+}
@@ -0,0 +1,106 @@
+package typedhandler
+
+import (
+	"net/url"
+	"strings"
+)
+
+type (
+	// queryKey is the query field bound to a key of the query string
+	queryKey struct {
+		index int  // index of the binding in the query fields
+		alias bool // key is the alias of the binding (e.g. "filter[name]"), used when the name is absent
+	}
+
+	// queryValues holds the raw values of the query fields found in a query string, by the index of their binding
+	queryValues struct {
+		first []string   // first value of single-valued fields
+		multi [][]string // all the values of multi-valued fields
+		found []uint8    // key of each field found in the query: 0 (absent), keyAlias or keyName
+	}
+)
+
+// Keys of a query field found in the query string, by priority
+const (
+	keyAlias uint8 = iota + 1
+	keyName
+)
+
+// queryStackFields is the number of query fields whose values are held on the stack while parsing
+const queryStackFields = 32
+
+// indexQueryFields maps the names and aliases of the query fields to their bindings
+func (sh *SchemaHelper[RIn]) indexQueryFields() {
+	sh.queryKeys = make(map[string]queryKey, len(sh.queryFields))
+
+	for i := range sh.queryFields {
+		binding := &sh.queryFields[i]
+		if binding.alias != "" {
+			sh.queryKeys[binding.alias] = queryKey{index: i, alias: true}
+		}
+
+		sh.queryKeys[binding.name] = queryKey{index: i}
+	}
+}
+
+// scanQuery scans the raw query for the keys of the query fields, once per request.
+// Unknown keys are skipped, and keys and values are only unescaped (and allocated) when they have escapes.
+// Like url.ParseQuery, pairs with semicolons or invalid escapes are skipped
+func (sh *SchemaHelper[RIn]) scanQuery(rawQuery string, values *queryValues) {
+	for rawQuery != "" {
+		var pair string
+
+		pair, rawQuery, _ = strings.Cut(rawQuery, "&")
+		if pair == "" || strings.Contains(pair, ";") {
+			continue
+		}
+
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			continue
+		}
+
+		found, ok := sh.queryKeys[key]
+		if !ok {
+			continue
+		}
+
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			continue
+		}
+
+		values.add(sh.queryFields[found.index].multi, found, value)
+	}
+}
+
+// add adds the value of the key of a query field: the name of the field takes priority over its alias,
+// single-valued fields keep the first value, and multi-valued fields keep all the values of the same key
+func (v *queryValues) add(multi bool, key queryKey, value string) {
+	priority := keyName
+	if key.alias {
+		priority = keyAlias
+	}
+
+	i := key.index
+
+	switch {
+	case v.found[i] > priority:
+		return
+	case v.found[i] < priority:
+		v.found[i] = priority
+		v.first[i] = value
+
+		if multi {
+			if v.multi == nil {
+				v.multi = make([][]string, len(v.found))
+			}
+
+			v.multi[i] = append(v.multi[i][:0], value)
+		}
+	case multi:
+		v.multi[i] = append(v.multi[i], value)
+	}
+}
@@ -0,0 +1,143 @@
+package typedhandler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	queryFilter struct {
+		Name string `query:"name"`
+	}
+	queryRequest struct {
+		Search string      `query:"q"`
+		Page   int         `query:"page"`
+		Tags   []string    `query:"tag"`
+		IDs    []int       `query:"ids,explode=false"`
+		Filter queryFilter `query:"filter"`
+	}
+	queryRequest1 struct {
+		F1 string `query:"f1"`
+	}
+	queryRequest5 struct {
+		F1 string `query:"f1"`
+		F2 int    `query:"f2"`
+		F3 string `query:"f3"`
+		F4 int    `query:"f4"`
+		F5 bool   `query:"f5"`
+	}
+	queryRequest20 struct {
+		F1  string  `query:"f1"`
+		F2  int     `query:"f2"`
+		F3  string  `query:"f3"`
+		F4  int     `query:"f4"`
+		F5  bool    `query:"f5"`
+		F6  string  `query:"f6"`
+		F7  int     `query:"f7"`
+		F8  string  `query:"f8"`
+		F9  int     `query:"f9"`
+		F10 bool    `query:"f10"`
+		F11 string  `query:"f11"`
+		F12 int64   `query:"f12"`
+		F13 string  `query:"f13"`
+		F14 uint    `query:"f14"`
+		F15 float64 `query:"f15"`
+		F16 string  `query:"f16"`
+		F17 int     `query:"f17"`
+		F18 string  `query:"f18"`
+		F19 int     `query:"f19"`
+		F20 bool    `query:"f20"`
+	}
+)
+
+func TestSchemaHelper_parseRequestQuery(t *testing.T) {
+	t.Parallel()
+
+	sh := GetSchemaHelper[*queryRequest]()
+	tests := []struct {
+		name     string
+		query    string
+		expected queryRequest
+	}{
+		{"empty", "", queryRequest{}},
+		{"escapes", "q=hello+big%20world&page=2", queryRequest{Search: "hello big world", Page: 2}},
+		{"escaped_key", "%71=x", queryRequest{Search: "x"}},
+		{"first_value", "q=a&q=b&page=1&page=x", queryRequest{Search: "a", Page: 1}},
+		{"repeated_keys", "tag=a&ids=1,2&tag=b&ids=3", queryRequest{Tags: []string{"a", "b"}, IDs: []int{1, 2, 3}}},
+		{"unknown_and_empty_pairs", "x=1&&tag&=2&q", queryRequest{Tags: []string{""}}},
+		{"semicolons_and_invalid_escapes", "q=a;b&q=%zz&q=ok", queryRequest{Search: "ok"}},
+		{"alias", "filter[name]=john", queryRequest{Filter: queryFilter{Name: "john"}}},
+		{"name_over_alias", "filter[name]=alias&filter.name=name&filter[name]=again",
+			queryRequest{Filter: queryFilter{Name: "name"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			instance := sh.GetInstance()
+			defer sh.PutInstance(instance)
+
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			require.NoError(t, sh.parseRequestQuery(r, reflect.ValueOf(instance).Elem()))
+			assert.Equal(t, tt.expected, *instance)
+		})
+	}
+}
+
+func TestSchemaHelper_scanQuery(t *testing.T) {
+	t.Parallel()
+
+	sh := GetSchemaHelper[*queryRequest]()
+
+	// allocated values, like the ones of structs with more fields than queryStackFields
+	values := queryValues{first: make([]string, len(sh.queryFields)), found: make([]uint8, len(sh.queryFields))}
+	sh.scanQuery("tag=a&filter[name]=x&q=1&tag=b&filter.name=y", &values)
+
+	assert.Equal(t, []uint8{keyName, 0, keyName, 0, keyName}, values.found)
+	assert.Equal(t, []string{"1", "", "a", "", "y"}, values.first)
+	assert.Equal(t, []string{"a", "b"}, values.multi[2])
+}
+
+// BenchmarkSchemaHelper_parseRequestQuery measures the query parsing of structs with 1, 5 and 20 query fields
+// The only allocations are the unescaped string values ("value%20f1")
+func BenchmarkSchemaHelper_parseRequestQuery(b *testing.B) {
+	b.Run("fields_1", benchmarkParseRequestQuery[*queryRequest1])
+	b.Run("fields_5", benchmarkParseRequestQuery[*queryRequest5])
+	b.Run("fields_20", benchmarkParseRequestQuery[*queryRequest20])
+}
+
+func benchmarkParseRequestQuery[RIn RequestSchema](b *testing.B) {
+	b.ReportAllocs()
+
+	sh := GetSchemaHelper[RIn]()
+	instance := sh.GetInstance()
+	structValue := reflect.ValueOf(instance).Elem()
+
+	pairs := make([]string, len(sh.queryFields))
+	for i, binding := range sh.queryFields {
+		switch structValue.FieldByIndex(binding.index).Kind() {
+		case reflect.String:
+			pairs[i] = binding.name + "=value%20" + binding.name
+		case reflect.Bool:
+			pairs[i] = binding.name + "=true"
+		default:
+			pairs[i] = fmt.Sprintf("%s=%d", binding.name, i)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?unknown=1&"+strings.Join(pairs, "&"), nil)
+
+	for b.Loop() {
+		if err := sh.parseRequestQuery(r, structValue); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,35 +1,49 @@
 package typedhandler
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
-
-	"github.com/go-playground/validator/v10"
 )
 
 // SchemaHelper is a helper for request schema RIn
 type (
 	SchemaHelper[RIn RequestSchema] struct {
-		queryFields  map[int]string // query fields
-		pathFields   map[int]string // path fields
-		headerFields map[int]string // header fields
+		queryFields  []fieldBinding // query fields
+		pathFields   []fieldBinding // path fields
+		headerFields []fieldBinding // header fields
+		cookieFields []fieldBinding // cookie fields
+		formFields   []fieldBinding // form fields
+		fileFields   []fieldBinding // multipart file fields
+
+		queryKeys map[string]queryKey // query fields by name and alias
 
 		typeFor       reflect.Type
+		config        *Config // settings of the helper
 		bodyType      BodyType
 		ResetFunc     func(RIn)
-		parseBodyFunc func(r *http.Request, instance any) error
-		validateFunc  func(RIn) error
+		parseBodyFunc func(r *http.Request, instance any, codecFor bodyCodecFunc) error
+		codecFor      bodyCodecFunc      // codecs of the config, bound once to not allocate per request
+		bodyFieldType reflect.Type       // type of the pointer returned by GetBodyField
+		bodyFields    []string           // names of the fields filled by the body
+		validateFunc  validateFunc[RIn]  // validates the instance, except the named fields
+		preParseFunc  parseHookFunc[RIn] // PreParseable hook, nil if not implemented
+		postParseFunc parseHookFunc[RIn] // PostParseable hook, nil if not implemented
 
 		instancePool sync.Pool
 		poolGetFunc  func() any
 		poolPutFunc  func(any)
 
+		maxMemory int64 // max memory for multipart forms, the remaining is stored in temporary files
+
 		hasValidate bool
 		errors      error
 
@@ -37,12 +51,25 @@ type (
 	}
 
 	BodyType uint8
+
+	// parseHookFunc calls a parse hook of the instance of a request
+	parseHookFunc[RIn RequestSchema] func(r *http.Request, instance RIn) error
+
+	// validateFunc validates the instance of a request, except the named fields
+	validateFunc[RIn RequestSchema] func(ctx context.Context, instance RIn, except ...string) error
 )
 
 const (
-	NoBody    BodyType = iota // No body from request
-	JsonBody                  // Body is unmarhaled into hole struct
-	JsonField                 // Body is unmarshaled into struct field referenced by BodyFieldGetter interface
+	NoBody        BodyType = iota // No body from request
+	JsonBody                      // Body is unmarhaled into hole struct
+	JsonField                     // Body is unmarshaled into struct field referenced by BodyFieldGetter interface
+	FormBody                      // Body is an urlencoded form, parsed into fields with "form" tags
+	MultipartBody                 // Body is a multipart form, parsed into fields with "form" and "file" tags
+)
+
+const (
+	urlencodedMediaType = "application/x-www-form-urlencoded"
+	multipartMediaType  = "multipart/form-data"
 )
 
 var (
@@ -52,29 +79,36 @@ var (
 	PoolEnabled = true
 )
 
-// GetSchemaHelper returns the SchemaHelper for request schema RIn
-// It creates a new SchemaHelper if it does not exist
+// GetSchemaHelper returns the SchemaHelper for request schema RIn and the settings of the options
+// It creates a new SchemaHelper if it does not exist. The helpers are cached by type and settings:
+// parsers created with the same settings (like the ones created with the same Config) share the helper
 // RIn must be a pointer type
-func GetSchemaHelper[RIn RequestSchema]() *SchemaHelper[RIn] {
+func GetSchemaHelper[RIn RequestSchema](options ...Option) *SchemaHelper[RIn] {
+	return getSchemaHelper[RIn](NewConfig(options...))
+}
+
+// getSchemaHelper returns the SchemaHelper for request schema RIn and the settings of the config
+func getSchemaHelper[RIn RequestSchema](config *Config) *SchemaHelper[RIn] {
 	mustBeAPointer[RIn]()
 
 	shMu.Lock()
 	defer shMu.Unlock()
 
 	t := typeName[RIn]()
+	if settings := config.schemaKey(); settings != "" {
+		t += "{" + settings + "}"
+	}
 
 	instance, found := schemaHelpers[t]
 	if found {
 		return instance.(*SchemaHelper[RIn])
 	}
 
-	fieldCount := getType[RIn]().NumField()
 	// Create new SchemaHelper
 	helper := &SchemaHelper[RIn]{
-		queryFields:  make(map[int]string, fieldCount),
-		pathFields:   make(map[int]string, fieldCount),
-		headerFields: make(map[int]string, fieldCount),
-		typeFor:      reflect.TypeFor[RIn](),
+		typeFor:  reflect.TypeFor[RIn](),
+		config:   config,
+		codecFor: config.bodyCodec,
 	}
 	helper.initializeFields()
 
@@ -85,8 +119,10 @@ func GetSchemaHelper[RIn RequestSchema]() *SchemaHelper[RIn] {
 		panic(err)
 	}
 
+	helper.createJsonFastPath()
 	helper.createResetFunc()
 	helper.createValidateFunc()
+	helper.createParseHooks()
 	helper.createInstancePool()
 
 	schemaHelpers[t] = helper
@@ -99,8 +135,14 @@ func (sh *SchemaHelper[RIn]) GetInstance() RIn {
 	return sh.poolGetFunc().(RIn)
 }
 
-// PutInstance returns an instance of RIn to the pool
+// PutInstance resets an instance of RIn and returns it to the pool
+// A nil instance is ignored
 func (sh *SchemaHelper[RIn]) PutInstance(instance RIn) {
+	var zero RIn
+	if any(instance) == any(zero) {
+		return
+	}
+
 	sh.ResetFunc(instance)
 	sh.poolPutFunc(instance)
 }
@@ -128,22 +170,73 @@ func (sh *SchemaHelper[RIn]) Errors() error {
 
 // initializeFields initializes the SchemaHelper fields by inspecting the struct tags
 func (sh *SchemaHelper[RIn]) initializeFields() {
-	t := getType[RIn]()
 	instance := sh.newInstance()
 
+	sh.checkValidator()
+	sh.walkFields(getType[RIn](), nil, nil, nil, instance)
+	sh.checkDominantFields()
+	sh.compileSetters()
+	sh.checkDefaults()
+	sh.indexQueryFields()
+	sh.checkParseableFields(instance)
+	sh.checkMultipartMemory(instance)
+}
+
+// walkFields inspects the fields of the struct type t.
+// Embedded structs are flattened, the way encoding/json does it, and nested structs tagged with
+// "query" or "form" are walked with the tag value as a prefix for the names of their query fields.
+// The index and the names of the parent fields (embedded or not) are prepended to the index and the name of each field
+func (sh *SchemaHelper[RIn]) walkFields(t reflect.Type, index []int, names []string, queryPrefix []string,
+	instance any,
+) {
 	for i := range t.NumField() {
 		field := t.Field(i)
-		if field.IsExported() {
+		field.Index = append(slices.Clone(index), i)
+		field.Name = strings.Join(append(slices.Clone(names), field.Name), ".")
+
+		if embedded := embeddedStruct(&field); embedded != nil {
+			sh.walkFields(embedded, field.Index, []string{field.Name}, queryPrefix, instance)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		registerOptionalValidation(sh.config.playgroundValidator(), field.Type)
+
+		if prefix := queryTagName(&field); prefix != "" && isNestedStruct(field.Type) {
 			sh.checkValidate(&field).
-				checkQuery(&field).
-				checkPath(&field).
-				checkHeader(&field).
-				checkJson(&field).
-				checkBody(&field, instance)
+				walkFields(field.Type, field.Index, []string{field.Name}, append(slices.Clone(queryPrefix), prefix),
+					instance)
+
+			continue
 		}
+
+		sh.checkValidate(&field).
+			checkQuery(&field, queryPrefix).
+			checkForm(&field, queryPrefix).
+			checkPath(&field).
+			checkHeader(&field).
+			checkCookie(&field).
+			checkFile(&field).
+			checkJson(&field).
+			checkBody(&field, instance)
 	}
+}
 
-	sh.checkParseableFields(instance)
+// checkValidator registers the translations of SetTranslator and of the translator of the config
+// into the validator of the config
+func (sh *SchemaHelper[RIn]) checkValidator() {
+	if v := sh.config.playgroundValidator(); v != nil {
+		if err := useValidator(v); err != nil {
+			sh.errors = errors.Join(sh.errors, fmt.Errorf("validator translations: %w", err))
+		}
+
+		if err := useTranslator(sh.config.translator, v); err != nil {
+			sh.errors = errors.Join(sh.errors, fmt.Errorf("validator translations: %w", err))
+		}
+	}
 }
 
 // checkValidate identifies if the struct has any validate tags
@@ -155,14 +248,23 @@ func (sh *SchemaHelper[RIn]) checkValidate(field *reflect.StructField) *SchemaHe
 	return sh
 }
 
-// checkQuery identifies query fields from struct tags "form" and "query"
-func (sh *SchemaHelper[RIn]) checkQuery(field *reflect.StructField) *SchemaHelper[RIn] {
-	if formField := field.Tag.Get("form"); formField != "" {
-		sh.queryFields[field.Index[0]] = formField
+// checkQuery identifies query fields from struct tags "query"
+func (sh *SchemaHelper[RIn]) checkQuery(field *reflect.StructField, prefix []string) *SchemaHelper[RIn] {
+	if queryField := field.Tag.Get("query"); queryField != "" {
+		sh.queryFields = append(sh.queryFields, newFieldBinding(SourceQuery, field, queryField, prefix))
 	}
 
-	if queryField := field.Tag.Get("query"); queryField != "" {
-		sh.queryFields[field.Index[0]] = queryField
+	return sh
+}
+
+// checkForm identifies form fields from struct tags "form"
+// A struct with form fields and no json fields has a FormBody
+func (sh *SchemaHelper[RIn]) checkForm(field *reflect.StructField, prefix []string) *SchemaHelper[RIn] {
+	if formField := field.Tag.Get("form"); formField != "" {
+		sh.formFields = append(sh.formFields, newFieldBinding(SourceForm, field, formField, prefix))
+		if sh.bodyType == NoBody {
+			sh.bodyType = FormBody
+		}
 	}
 
 	return sh
@@ -171,29 +273,47 @@ func (sh *SchemaHelper[RIn]) checkQuery(field *reflect.StructField) *SchemaHelpe
 // checkPath identifies path fields from struct tags "path"
 func (sh *SchemaHelper[RIn]) checkPath(field *reflect.StructField) *SchemaHelper[RIn] {
 	if pathParam := field.Tag.Get("path"); pathParam != "" {
-		sh.pathFields[field.Index[0]] = pathParam
+		binding := newFieldBinding(SourcePath, field, pathParam, nil)
+		binding.status = sh.config.pathErrorStatus
+		sh.pathFields = append(sh.pathFields, binding)
 	}
 
 	return sh
 }
 
+// checkHeader identifies header fields from struct tags "header"
+// A header field must have a supported type, or be a slice/array of a supported type for multi-value headers
 func (sh *SchemaHelper[RIn]) checkHeader(field *reflect.StructField) *SchemaHelper[RIn] {
 	if headerField := field.Tag.Get("header"); headerField != "" {
-		if field.Type.Kind() != reflect.String {
-			sh.errors = errors.Join(sh.errors, fmt.Errorf("header field %s must be a string", field.Name))
+		if !isConvertible(field.Type) {
+			sh.errors = errors.Join(sh.errors,
+				fmt.Errorf("header field %s: %w", field.Name, unsupportedTypeError(field.Type)))
 		} else {
-			sh.headerFields[field.Index[0]] = headerField
+			sh.headerFields = append(sh.headerFields, newFieldBinding(SourceHeader, field, headerField, nil))
 		}
 	}
 
 	return sh
 }
 
+// checkCookie identifies cookie fields from struct tags "cookie"
+// A *http.Cookie field receives the whole cookie, other fields receive the cookie value
+func (sh *SchemaHelper[RIn]) checkCookie(field *reflect.StructField) *SchemaHelper[RIn] {
+	if cookieField := field.Tag.Get("cookie"); cookieField != "" {
+		binding := newFieldBinding(SourceCookie, field, cookieField, nil)
+		binding.httpCookie = field.Type == cookieType
+		sh.cookieFields = append(sh.cookieFields, binding)
+	}
+
+	return sh
+}
+
 func (sh *SchemaHelper[RIn]) checkJson(field *reflect.StructField) *SchemaHelper[RIn] {
 	if jsonBody := field.Tag.Get("json"); jsonBody != "" {
 		// a json tag implies that the request body will be parsed into hole instance
 		sh.bodyType = JsonBody
 		sh.parseBodyFunc = parseBodyInstance
+		sh.bodyFields = append(sh.bodyFields, field.Name)
 	}
 
 	return sh
@@ -233,42 +353,140 @@ func (sh *SchemaHelper[RIn]) checkBody(field *reflect.StructField, instance any)
 	}
 
 	sh.parseBodyFunc = parseBodyField
+	sh.bodyFieldType = t
+	sh.bodyFields = []string{field.Name}
+
+	if isRawBody(field.Type) {
+		sh.parseBodyFunc = parseRawBodyField
+	}
+
 	sh.bodyType = JsonField
 
 	return sh
 }
 
+// checkDefaults checks that the values of the "default" tags can be converted into the types of their fields
+func (sh *SchemaHelper[RIn]) checkDefaults() {
+	t := getType[RIn]()
+
+	for _, bindings := range [][]fieldBinding{
+		sh.queryFields, sh.pathFields, sh.headerFields, sh.cookieFields, sh.formFields,
+	} {
+		for i := range bindings {
+			if err := bindings[i].checkDefault(t.FieldByIndex(bindings[i].index).Type); err != nil {
+				sh.errors = errors.Join(sh.errors, err)
+			}
+		}
+	}
+}
+
+// compileSetters resolves the converters of the fields and precompiles the setters of the single-valued fields,
+// so the requests are parsed without resolving the types of the fields again
+func (sh *SchemaHelper[RIn]) compileSetters() {
+	t := getType[RIn]()
+	parseTime := sh.config.timeParser()
+
+	for _, bindings := range [][]fieldBinding{
+		sh.queryFields, sh.pathFields, sh.headerFields, sh.cookieFields, sh.formFields,
+	} {
+		for i := range bindings {
+			binding := &bindings[i]
+			if binding.multi {
+				binding.convertValues = valuesConverterFor(binding.typ, parseTime)
+				continue
+			}
+
+			binding.convert = elementConverter(binding.typ, parseTime)
+			if !binding.httpCookie {
+				binding.set = newFieldSetter(t, binding.index, binding.convert, parseTime)
+			}
+		}
+	}
+}
+
+// checkDominantFields removes the fields hidden by shallower fields bound to the same name
+func (sh *SchemaHelper[RIn]) checkDominantFields() {
+	for _, bindings := range []*[]fieldBinding{
+		&sh.queryFields, &sh.pathFields, &sh.headerFields, &sh.cookieFields, &sh.formFields, &sh.fileFields,
+	} {
+		var err error
+		if *bindings, err = dominantBindings(*bindings); err != nil {
+			sh.errors = errors.Join(sh.errors, err)
+		}
+	}
+}
+
 func (sh *SchemaHelper[RIn]) checkParseableFields(instance any) {
 	value := reflect.ValueOf(instance)
 	t := reflect.TypeOf(instance)
-	visited := make(map[int]struct{})
-	checkPF := func(f map[int]string) {
-		for fieldIndex := range f {
-			if _, ok := visited[fieldIndex]; ok {
+	visited := make(map[string]struct{})
+	checkPF := func(bindings []fieldBinding) {
+		for _, binding := range bindings {
+			if _, ok := visited[binding.field]; ok {
 				continue
 			}
 
-			field := value.Elem().Field(fieldIndex)
+			field := fieldByIndex(value.Elem(), binding.index)
 			slog.Debug("checkParseableFields", slog.String("type", t.String()), slog.String("field", field.String()))
 
-			if !field.CanSet() { //!value.Elem().Field(fieldIndex).CanSet() {
+			if !field.CanSet() {
 				sh.errors = errors.Join(
 					sh.errors,
-					fmt.Errorf("field %s is not settable", sh.typeFor.Field(fieldIndex).Name),
+					fmt.Errorf("field %s is not settable", binding.field),
 				)
 			}
 
-			visited[fieldIndex] = struct{}{}
+			visited[binding.field] = struct{}{}
 		}
 	}
 	checkPF(sh.queryFields)
 	checkPF(sh.pathFields)
 	checkPF(sh.headerFields)
+	checkPF(sh.cookieFields)
+	checkPF(sh.formFields)
+	checkPF(sh.fileFields)
+}
+
+// createJsonFastPath decodes JSON bodies with the easyjson.Unmarshaler or json.Unmarshaler
+// implemented by the body target (the instance or its body field), instead of the JSONCodec reflection
+// The fast path is used only when the request is decoded by the default JSONCodec
+func (sh *SchemaHelper[RIn]) createJsonFastPath() {
+	var (
+		target      = sh.typeFor
+		bodyTarget  = func(instance any) any { return instance }
+		parseBodyFn = sh.parseBodyFunc
+	)
+
+	switch sh.bodyType {
+	case JsonBody:
+	case JsonField:
+		if isRawBody(sh.bodyFieldType.Elem()) {
+			return
+		}
+
+		target = sh.bodyFieldType
+		bodyTarget = func(instance any) any { return instance.(BodyFieldGetter).GetBodyField() }
+	default:
+		return
+	}
+
+	decode := jsonFastDecoder(target)
+	if decode == nil {
+		return
+	}
+
+	sh.parseBodyFunc = func(r *http.Request, instance any, codecFor bodyCodecFunc) error {
+		if codec, err := codecFor(mediaType(r)); err != nil || codec != BodyCodec(JSONCodec{}) {
+			return parseBodyFn(r, instance, codecFor)
+		}
+
+		return decode(r.Body, bodyTarget(instance))
+	}
 }
 
 func (sh *SchemaHelper[RIn]) createResetFunc() {
-	if (len(sh.headerFields) + len(sh.queryFields) + len(sh.pathFields)) == 0 {
-		// Only create reset function if we have non-body fields that need clearing
+	if getType[RIn]().NumField() == 0 {
+		// Nothing to clear
 		sh.ResetFunc = func(RIn) {} // NOOP
 		return
 	}
@@ -308,31 +526,85 @@ func (sh *SchemaHelper[RIn]) createResetFunc() {
 	panic("value type RIn in SchemaHelper createResetFunc")
 }
 
+// createValidateFunc creates the validation pipeline of the instances: the validation of the "validate" tags
+// (if any), then Validatable.Validate and ContextValidatable.ValidateContext (if implemented).
+// Every stage runs, and the errors of more than one stage are combined into RequestErrors
 func (sh *SchemaHelper[RIn]) createValidateFunc() {
-	if !sh.hasValidate {
-		// No validate tags found, no validate function needed
-		sh.validateFunc = func(RIn) error { return nil } // NOOP
-		return
+	var (
+		zero   RIn
+		stages []validateFunc[RIn]
+	)
+
+	if sh.hasValidate {
+		v := sh.config.structValidator()
+		structType := getType[RIn]()
+		stages = append(stages, func(_ context.Context, instance RIn, except ...string) error {
+			if len(except) > 0 {
+				return withRequestNamespaces(v.StructExcept(instance, except...), structType)
+			}
+
+			return withRequestNamespaces(v.Struct(instance), structType)
+		})
 	}
 
-	var zero RIn
-	if validatable, ok := any(zero).(Validatable); ok {
-		sh.validateFunc = func(instance RIn) error {
-			return validatable.Validate()
-		}
+	if _, ok := any(zero).(Validatable); ok {
+		stages = append(stages, func(_ context.Context, instance RIn, _ ...string) error {
+			return any(instance).(Validatable).Validate()
+		})
+	}
 
-		return
+	if _, ok := any(zero).(ContextValidatable); ok {
+		stages = append(stages, func(ctx context.Context, instance RIn, _ ...string) error {
+			return any(instance).(ContextValidatable).ValidateContext(ctx)
+		})
+	}
+
+	switch len(stages) {
+	case 0:
+		sh.validateFunc = func(context.Context, RIn, ...string) error { return nil } // NOOP
+	case 1:
+		sh.validateFunc = stages[0]
+	default:
+		sh.validateFunc = func(ctx context.Context, instance RIn, except ...string) error {
+			var errs []error
+
+			for _, stage := range stages {
+				if err := stage(ctx, instance, except...); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			switch len(errs) {
+			case 0:
+				return nil
+			case 1:
+				return errs[0]
+			default:
+				return RequestErrors(errs)
+			}
+		}
 	}
+}
+
+// createParseHooks creates the calls to the PreParseable and PostParseable hooks, if RIn implements them
+func (sh *SchemaHelper[RIn]) createParseHooks() {
+	var zero RIn
 
-	v := validator.New(validator.WithRequiredStructEnabled())
+	if _, ok := any(zero).(PreParseable); ok {
+		sh.preParseFunc = func(r *http.Request, instance RIn) error {
+			return any(instance).(PreParseable).PreParse(r)
+		}
+	}
 
-	sh.validateFunc = func(instance RIn) error {
-		return v.Struct(instance)
+	if _, ok := any(zero).(PostParseable); ok {
+		sh.postParseFunc = func(r *http.Request, instance RIn) error {
+			return any(instance).(PostParseable).PostParse(r)
+		}
 	}
 }
 
 func (sh *SchemaHelper[RIn]) createInstancePool() {
-	if PoolEnabled {
+	if sh.config.poolEnabled() {
 		sh.instancePool = sync.Pool{
 			New: func() any {
 				sh.instanceCount.Add(1)
@@ -354,21 +626,101 @@ func (sh *SchemaHelper[RIn]) createInstancePool() {
 
 // parseRequestBody parses the body from request and sets the values in the struct
 // The body can be JSON unmarshaled into the whole struct or into a struct field
+// Urlencoded and multipart form bodies are parsed by parseRequestForm
 func (sh *SchemaHelper[RIn]) parseRequestBody(r *http.Request, instance RIn) error {
-	if sh.bodyType != NoBody {
-		return sh.parseBodyFunc(r, instance)
+	if sh.parseBodyFunc != nil && sh.formRequestType(r) == "" {
+		return newBodyParseError(sh.parseBodyFunc(r, instance, sh.codecFor))
 	}
 
 	return nil
 }
 
+// parseRequestForm parses the form and sets the values in the struct
+// When the request body is an urlencoded or multipart form, the form fields receive the values from the body,
+// otherwise they receive the values from the query string
+func (sh *SchemaHelper[RIn]) parseRequestForm(r *http.Request, structValue reflect.Value) (err error) {
+	if len(sh.formFields) == 0 && len(sh.fileFields) == 0 {
+		return nil
+	}
+
+	var values url.Values
+
+	switch sh.formRequestType(r) {
+	case urlencodedMediaType:
+		if err = r.ParseForm(); err != nil {
+			return newBodyParseError(err)
+		}
+
+		values = r.PostForm
+	case multipartMediaType:
+		if values, err = sh.parseMultipartForm(r, structValue); err != nil {
+			return newBodyParseError(err)
+		}
+	default:
+		values = r.URL.Query()
+	}
+
+	aggregate := sh.config.aggregateParseErrors()
+	for i := range sh.formFields {
+		binding := &sh.formFields[i]
+		if joinParseError(&err, binding.bind(structValue, binding.lookup(values)), aggregate) {
+			break
+		}
+	}
+
+	return err
+}
+
+// formRequestType returns the media type of the request body, when it is an urlencoded or multipart form
+// to be parsed into the form (and file) fields, or an empty string otherwise
+func (sh *SchemaHelper[RIn]) formRequestType(r *http.Request) string {
+	if len(sh.formFields) == 0 && len(sh.fileFields) == 0 {
+		return ""
+	}
+
+	if mt := mediaType(r); mt == urlencodedMediaType || mt == multipartMediaType {
+		return mt
+	}
+
+	return ""
+}
+
 // parseRequestHeaders parses the headers and sets the values in the struct
-// A header value is always a string
+// A header value is always a string. Slices and arrays receive all the values of the header
 func (sh *SchemaHelper[RIn]) parseRequestHeaders(r *http.Request, structValue reflect.Value) (err error) {
-	var header string
-	for key, value := range sh.headerFields {
-		header = r.Header.Get(value)
-		if err = convertData(header, int(key), structValue); err != nil {
+	aggregate := sh.config.aggregateParseErrors()
+	for i := range sh.headerFields {
+		binding := &sh.headerFields[i]
+		if joinParseError(&err, binding.bind(structValue, r.Header.Values(binding.name)), aggregate) {
+			break
+		}
+	}
+
+	return err
+}
+
+// parseRequestCookies parses the cookies and sets the values in the struct
+// A *http.Cookie field receives the whole cookie.
+// Slices and arrays receive the values of all the cookies with the same name
+func (sh *SchemaHelper[RIn]) parseRequestCookies(r *http.Request, structValue reflect.Value) (err error) {
+	aggregate := sh.config.aggregateParseErrors()
+	for i := range sh.cookieFields {
+		binding := &sh.cookieFields[i]
+
+		if binding.httpCookie {
+			if cookie, cookieErr := r.Cookie(binding.name); cookieErr == nil {
+				fieldByIndex(structValue, binding.index).Set(reflect.ValueOf(cookie))
+			}
+
+			continue
+		}
+
+		var values []string
+		for _, cookie := range r.CookiesNamed(binding.name) {
+			values = append(values, cookie.Value)
+		}
+
+		if joinParseError(&err, binding.bind(structValue, values), aggregate) {
 			break
 		}
 	}
@@ -377,12 +729,18 @@ func (sh *SchemaHelper[RIn]) parseRequestHeaders(r *http.Request, structValue re
 }
 
 // parseRequestPath parses the path and sets the values in the struct
-// A path value can be: string, int, uint, float64, bool, time.Time, time.Duration
+// A path value can be: string, int, uint, float64, bool, time.Time, time.Duration, or a slice/array of them
 func (sh *SchemaHelper[RIn]) parseRequestPath(r *http.Request, structValue reflect.Value) (err error) {
-	var path string
-	for key, value := range sh.pathFields {
-		path = r.PathValue(value)
-		if err = convertData(path, int(key), structValue); err != nil {
+	aggregate := sh.config.aggregateParseErrors()
+	for i := range sh.pathFields {
+		binding := &sh.pathFields[i]
+
+		var values []string
+		if value := r.PathValue(binding.name); value != "" {
+			values = []string{value}
+		}
+
+		if joinParseError(&err, binding.bind(structValue, values), aggregate) {
 			break
 		}
 	}
@@ -391,12 +749,42 @@ func (sh *SchemaHelper[RIn]) parseRequestPath(r *http.Request, structValue refle
 }
 
 // parseRequestQuery parses the query and sets the values in the struct
-// A query value can be: string, int, uint, float64, bool, time.Time, time.Duration
+// A query value can be: string, int, uint, float64, bool, time.Time, time.Duration, or a slice/array of them
+// Slices and arrays receive all the values of repeated keys
+// The raw query is scanned once for the keys of the query fields, without building url.Values
 func (sh *SchemaHelper[RIn]) parseRequestQuery(r *http.Request, structValue reflect.Value) (err error) {
-	var query string
-	for key, value := range sh.queryFields {
-		query = r.URL.Query().Get(value)
-		if err = convertData(query, int(key), structValue); err != nil {
+	if len(sh.queryFields) == 0 {
+		return nil
+	}
+
+	var (
+		first  [queryStackFields]string
+		found  [queryStackFields]uint8
+		values = queryValues{first: first[:], found: found[:]}
+	)
+
+	if len(sh.queryFields) > queryStackFields {
+		values = queryValues{first: make([]string, len(sh.queryFields)), found: make([]uint8, len(sh.queryFields))}
+	}
+
+	sh.scanQuery(r.URL.RawQuery, &values)
+
+	aggregate := sh.config.aggregateParseErrors()
+	for i := range sh.queryFields {
+		binding := &sh.queryFields[i]
+
+		var bindErr error
+
+		switch {
+		case values.found[i] == 0:
+			bindErr = binding.bind(structValue, nil)
+		case binding.multi:
+			bindErr = binding.bind(structValue, values.multi[i])
+		default:
+			bindErr = binding.bindValue(structValue, values.first[i])
+		}
+
+		if joinParseError(&err, bindErr, aggregate) {
 			break
 		}
 	}
@@ -405,13 +793,19 @@ func (sh *SchemaHelper[RIn]) parseRequestQuery(r *http.Request, structValue refl
 }
 
 // parseBodyInstance parses the body from request into the instance
-func parseBodyInstance(r *http.Request, instance any) error {
-	return json.NewDecoder(r.Body).Decode(instance)
+// The body is decoded by the BodyCodec found by codecFor for the Content-Type of the request
+func parseBodyInstance(r *http.Request, instance any, codecFor bodyCodecFunc) error {
+	codec, err := codecFor(mediaType(r))
+	if err != nil {
+		return err
+	}
+
+	return codec.Decode(r.Body, instance)
 }
 
 // parseBodyField parses the body from request into the struct field returned by GetBodyField function
 // the instance must implement the BodyFieldGetter interface
-func parseBodyField(r *http.Request, instance any) error {
+func parseBodyField(r *http.Request, instance any, codecFor bodyCodecFunc) error {
 	rawInstance := instance
 
 	bfg, ok := rawInstance.(BodyFieldGetter)
@@ -427,5 +821,11 @@ func parseBodyField(r *http.Request, instance any) error {
 			typeString(instance))
 	}
 
-	return parseBodyInstance(r, bodyFieldValue)
+	return parseBodyInstance(r, bodyFieldValue, codecFor)
+}
+
+// parseRawBodyField passes the body from request through to the []byte or io.Reader field
+// returned by GetBodyField function, whatever the Content-Type of the request
+func parseRawBodyField(r *http.Request, instance any, _ bodyCodecFunc) error {
+	return RawCodec{}.Decode(r.Body, instance.(BodyFieldGetter).GetBodyField())
 }
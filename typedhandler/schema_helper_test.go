@@ -2,9 +2,15 @@ package typedhandler
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/guionardo/typedhandler/examples/sample"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,7 +18,7 @@ import (
 
 type (
 	RequestInvalidHeader struct {
-		AuthToken int `header:"Authorization"`
+		AuthToken map[string]string `header:"Authorization"`
 	}
 	StructWithUnsettableField struct {
 		UnsettableField *struct{ name string } `path:"unsettable_field"`
@@ -30,6 +36,18 @@ type (
 	body struct {
 		BodyField string `json:"body_field"`
 	}
+	tokenRequest struct {
+		GrantType string   `form:"grant_type"`
+		Scopes    []string `form:"scope"`
+		Client    string   `query:"client"`
+	}
+	cookieRequest struct {
+		SessionID string       `cookie:"session_id"`
+		Locale    string       `cookie:"locale"`
+		Visits    int          `cookie:"visits"`
+		CSRF      *http.Cookie `cookie:"csrf"`
+		Flags     []string     `cookie:"flag"`
+	}
 )
 
 func (r *resettableRequest) Reset() {
@@ -53,7 +71,8 @@ func TestSchemaHelper(t *testing.T) {
 	})
 	t.Run("Schema with invalid type for header", func(t *testing.T) {
 		t.Parallel()
-		require.Panics(t, func() {
+		require.PanicsWithError(t, "github.com/guionardo/typedhandler/typedhandler.RequestInvalidHeader: "+
+			"header field AuthToken: unsupported field type: map[string]string", func() {
 			_ = GetSchemaHelper[*RequestInvalidHeader]()
 		})
 	})
@@ -118,6 +137,82 @@ func TestSchemaHelper_CreateResetFunc(t *testing.T) {
 	})
 }
 
+func TestSchemaHelper_parseRequestCookies(t *testing.T) {
+	t.Parallel()
+
+	sh := GetSchemaHelper[*cookieRequest]()
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: "abc"})
+		req.AddCookie(&http.Cookie{Name: "visits", Value: "3"})
+		req.AddCookie(&http.Cookie{Name: "csrf", Value: "token"})
+		req.AddCookie(&http.Cookie{Name: "flag", Value: "a"})
+		req.AddCookie(&http.Cookie{Name: "flag", Value: "b"})
+
+		instance := sh.GetInstance()
+		defer sh.PutInstance(instance)
+
+		require.NoError(t, sh.parseRequestCookies(req, reflect.ValueOf(instance).Elem()))
+		assert.Equal(t, "abc", instance.SessionID)
+		assert.Empty(t, instance.Locale)
+		assert.Equal(t, 3, instance.Visits)
+		require.NotNil(t, instance.CSRF)
+		assert.Equal(t, "csrf", instance.CSRF.Name)
+		assert.Equal(t, "token", instance.CSRF.Value)
+		assert.Equal(t, []string{"a", "b"}, instance.Flags)
+	})
+	t.Run("conversion_error", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "visits", Value: "many"})
+
+		instance := sh.GetInstance()
+		defer sh.PutInstance(instance)
+
+		require.Error(t, sh.parseRequestCookies(req, reflect.ValueOf(instance).Elem()))
+	})
+}
+
+func TestSchemaHelper_parseRequestForm(t *testing.T) {
+	t.Parallel()
+
+	sh := GetSchemaHelper[*tokenRequest]()
+	assert.Equal(t, FormBody, sh.bodyType)
+	t.Run("urlencoded_body", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/token?client=web&grant_type=ignored",
+			strings.NewReader("grant_type=client_credentials&scope=read&scope=write"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+		instance := sh.GetInstance()
+		defer sh.PutInstance(instance)
+
+		structValue := reflect.ValueOf(instance).Elem()
+		require.NoError(t, sh.parseRequestBody(req, instance))
+		require.NoError(t, sh.parseRequestForm(req, structValue))
+		require.NoError(t, sh.parseRequestQuery(req, structValue))
+		assert.Equal(t, "client_credentials", instance.GrantType)
+		assert.Equal(t, []string{"read", "write"}, instance.Scopes)
+		assert.Equal(t, "web", instance.Client)
+	})
+	t.Run("query_string_without_form_body", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/token?grant_type=password", nil)
+
+		instance := sh.GetInstance()
+		defer sh.PutInstance(instance)
+
+		require.NoError(t, sh.parseRequestBody(req, instance))
+		require.NoError(t, sh.parseRequestForm(req, reflect.ValueOf(instance).Elem()))
+		assert.Equal(t, "password", instance.GrantType)
+	})
+}
+
 func Test_parseBodyInstance(t *testing.T) {
 	t.Parallel()
 
@@ -130,7 +225,7 @@ func Test_parseBodyInstance(t *testing.T) {
 
 	var responseBody parserType
 
-	err = parseBodyInstance(req, &responseBody)
+	err = parseBodyInstance(req, &responseBody, getBodyCodec)
 	require.NoError(t, err)
 	assert.Equal(t, "tester", responseBody.Name)
 }
@@ -149,7 +244,7 @@ func Test_parseBodyField(t *testing.T) {
 
 		var responseBody parserType
 
-		err = parseBodyField(req, &responseBody)
+		err = parseBodyField(req, &responseBody, getBodyCodec)
 		require.Error(t, err)
 	})
 	t.Run("with_get_body_fielder", func(t *testing.T) {
@@ -159,8 +254,97 @@ func Test_parseBodyField(t *testing.T) {
 		require.NoError(t, err)
 
 		rwf := requestWithBody{}
-		err = parseBodyField(req, &rwf)
+		err = parseBodyField(req, &rwf, getBodyCodec)
 		require.NoError(t, err)
 		assert.Equal(t, "tester", rwf.Body.BodyField)
 	})
 }
+
+type (
+	// pipelineRequest has the three validation stages
+	pipelineRequest struct {
+		Name  string `query:"name"  validate:"required"`
+		Email string `query:"email"`
+	}
+	// untaggedRequest implements Validatable, without "validate" tags
+	untaggedRequest struct {
+		Email string `query:"email"`
+	}
+	takenEmailKey struct{}
+)
+
+var errEmailTaken = httpError{StatusCode: http.StatusConflict, Message: "email is taken"}
+
+func (r *pipelineRequest) Validate() error {
+	if r.Email != strings.ToLower(r.Email) {
+		return httpError{StatusCode: http.StatusUnprocessableEntity, Message: "email must be lowercase"}
+	}
+
+	return nil
+}
+
+func (r *pipelineRequest) ValidateContext(ctx context.Context) error {
+	if taken, _ := ctx.Value(takenEmailKey{}).(string); taken != "" && taken == r.Email {
+		return errEmailTaken
+	}
+
+	return nil
+}
+
+func (r *untaggedRequest) Validate() error {
+	if r.Email == "" {
+		return errors.New("email is required")
+	}
+
+	return nil
+}
+
+func TestSchemaHelper_createValidateFunc(t *testing.T) {
+	t.Parallel()
+
+	parser, release := CreateParser[*pipelineRequest]()
+	parse := func(query string) error {
+		r := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		r = r.WithContext(context.WithValue(r.Context(), takenEmailKey{}, "taken@example.com"))
+
+		instance, err := parser(r)
+		release(instance)
+
+		return err
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+		require.NoError(t, parse("name=john&email=john@example.com"))
+	})
+	t.Run("single_stage", func(t *testing.T) {
+		t.Parallel()
+
+		err := parse("name=john&email=taken@example.com")
+		require.Equal(t, errEmailTaken, err, "the error of a single stage is returned as it is")
+	})
+	t.Run("every_stage", func(t *testing.T) {
+		t.Parallel()
+
+		err := parse("email=JOHN@example.com")
+
+		var requestErrors RequestErrors
+		require.ErrorAs(t, err, &requestErrors)
+		require.Len(t, requestErrors, 2)
+
+		var validationErrors validator.ValidationErrors
+		require.ErrorAs(t, requestErrors[0], &validationErrors)
+		assert.Equal(t, "name", validationErrors[0].Field())
+		require.EqualError(t, requestErrors[1], "email must be lowercase")
+		assert.Equal(t, http.StatusUnprocessableEntity, requestErrors.Status())
+	})
+	t.Run("without_tags", func(t *testing.T) {
+		t.Parallel()
+
+		parser, release := CreateParser[*untaggedRequest]()
+		instance, err := parser(httptest.NewRequest(http.MethodGet, "/", nil))
+		release(instance)
+
+		require.EqualError(t, err, "email is required")
+	})
+}
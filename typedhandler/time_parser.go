@@ -2,10 +2,14 @@ package typedhandler
 
 import (
 	"errors"
+	"slices"
 	"sync"
 	"time"
 )
 
+// timeParser parses a time string, like ParseTime
+type timeParser func(value string) (time.Time, error)
+
 var (
 	timeLayouts = []string{
 		time.DateTime,
@@ -36,27 +40,39 @@ func SetTimeLayouts(layouts []string) {
 // To avoid repeated parsing failures, it rearranges the layouts
 // to prioritize successful formats in future calls.
 func ParseTime(value string) (time.Time, error) {
-	timeLayoutsLock.RLock()
-	defer timeLayoutsLock.RUnlock()
+	timeLayoutsLock.Lock()
+	defer timeLayoutsLock.Unlock()
+
+	return parseTimeLayouts(timeLayouts, value)
+}
+
+// newTimeParser returns a timeParser with its own copy of the layouts, used instead of the configured layouts
+func newTimeParser(layouts []string) timeParser {
+	var lock sync.Mutex
+
+	layouts = slices.Clone(layouts)
 
-	if len(timeLayouts) == 0 {
+	return func(value string) (time.Time, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return parseTimeLayouts(layouts, value)
+	}
+}
+
+// parseTimeLayouts parses the value with the first matching layout, and moves it to the front of the layouts
+func parseTimeLayouts(layouts []string, value string) (time.Time, error) {
+	if len(layouts) == 0 {
 		return time.Time{}, ErrNoTimeLayouts
 	}
 
-	for index, layout := range timeLayouts {
+	for index, layout := range layouts {
 		t, err := time.Parse(layout, value)
 		if err == nil {
-			rearrangeLayoutToFront(index)
+			layouts[0], layouts[index] = layouts[index], layouts[0]
 			return t, nil
 		}
 	}
 
 	return time.Time{}, ErrTimeParsing
 }
-
-func rearrangeLayoutToFront(index int) {
-	if index == 0 {
-		return
-	}
-	timeLayouts[0], timeLayouts[index] = timeLayouts[index], timeLayouts[0]
-}
@@ -0,0 +1,322 @@
+package typedhandler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	pt_BR_translations "github.com/go-playground/validator/v10/translations/pt_BR"
+)
+
+type (
+	// LocaleResolver returns the locales preferred by the client of the request, in order of preference
+	LocaleResolver func(r *http.Request) []string
+
+	// RegisterTranslationsFunc registers the validation messages of a locale into the validator,
+	// like the RegisterDefaultTranslations funcs of the validator/v10/translations packages
+	RegisterTranslationsFunc func(v *validator.Validate, trans ut.Translator) error
+
+	// Translator translates validation messages into the locale of the request
+	Translator struct {
+		// Resolver returns the preferred locales of the request (default: AcceptLanguage)
+		Resolver LocaleResolver
+
+		universal    *ut.UniversalTranslator
+		fallback     ut.Translator
+		locales      []string
+		translations map[string]RegisterTranslationsFunc
+	}
+
+	// translatorKey is the key of the translator of a handler in the context of its requests
+	translatorKey struct{}
+
+	// validatorsTranslator is the ut.Translator of a locale registered into more than one validator:
+	// the texts added again by the registration into other validators replace the ones already added,
+	// instead of failing as conflicts
+	validatorsTranslator struct {
+		ut.Translator
+	}
+)
+
+var (
+	// defaultTranslations are the validator translations registered for the locales with the same name
+	defaultTranslations = map[string]RegisterTranslationsFunc{
+		"en":    en_translations.RegisterDefaultTranslations,
+		"es":    es_translations.RegisterDefaultTranslations,
+		"pt_BR": pt_BR_translations.RegisterDefaultTranslations,
+	}
+
+	activeTranslator *Translator
+	translatorLock   sync.RWMutex
+
+	sharedValidator     *validator.Validate
+	sharedValidatorOnce sync.Once
+	configValidators    []*validator.Validate // validators set by WithValidator, that receive the translations
+)
+
+// NewTranslator creates a Translator for the fallback locale and the supported locales
+// The default validator translations are used for "en", "es" and "pt_BR":
+//
+//	translator := typedhandler.NewTranslator(en.New(), pt_BR.New(), es.New())
+func NewTranslator(fallback locales.Translator, supported ...locales.Translator) *Translator {
+	universal := ut.New(fallback, append([]locales.Translator{fallback}, supported...)...)
+	translator := &Translator{
+		Resolver:     AcceptLanguage,
+		universal:    universal,
+		translations: make(map[string]RegisterTranslationsFunc),
+	}
+	translator.fallback = translator.getTranslator(fallback.Locale())
+
+	for _, locale := range append([]locales.Translator{fallback}, supported...) {
+		if !slices.Contains(translator.locales, locale.Locale()) {
+			translator.locales = append(translator.locales, locale.Locale())
+		}
+	}
+
+	return translator
+}
+
+// RegisterTranslations sets the func that registers the validation messages of the locale,
+// replacing the default translations. Locales without translations use the default messages
+func (t *Translator) RegisterTranslations(locale string, register RegisterTranslationsFunc) *Translator {
+	t.translations[locale] = register
+	return t
+}
+
+// SetTranslator registers the translations of the translator into the validators of the request schemas
+// (the shared validator and the validators set by WithValidator), and uses it to translate the validation
+// error messages. A nil translator disables the translation
+// It should be called before the handlers receive requests. WithTranslator sets the translator of a handler
+func SetTranslator(translator *Translator) error {
+	translatorLock.Lock()
+	defer translatorLock.Unlock()
+
+	if translator != nil {
+		for _, v := range append([]*validator.Validate{getValidator()}, configValidators...) {
+			if err := translator.register(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	activeTranslator = translator
+
+	return nil
+}
+
+// register registers the translations of each locale into the validator
+func (t *Translator) register(v *validator.Validate) error {
+	var err error
+
+	for _, locale := range t.locales {
+		register, found := t.translations[locale]
+		if !found {
+			register, found = defaultTranslations[locale]
+		}
+
+		if !found {
+			continue
+		}
+
+		err = errors.Join(err, register(v, t.getTranslator(locale)))
+	}
+
+	return err
+}
+
+// getTranslator returns the translator of the supported locale
+// The validators find the translations by translator, so the same translator is registered and used to translate
+func (t *Translator) getTranslator(locale string) ut.Translator {
+	trans, _ := t.universal.GetTranslator(locale)
+	return validatorsTranslator{trans}
+}
+
+func (t validatorsTranslator) Add(key any, text string, _ bool) error {
+	return t.Translator.Add(key, text, true)
+}
+
+func (t validatorsTranslator) AddCardinal(key any, text string, rule locales.PluralRule, _ bool) error {
+	return t.Translator.AddCardinal(key, text, rule, true)
+}
+
+func (t validatorsTranslator) AddOrdinal(key any, text string, rule locales.PluralRule, _ bool) error {
+	return t.Translator.AddOrdinal(key, text, rule, true)
+}
+
+func (t validatorsTranslator) AddRange(key any, text string, rule locales.PluralRule, _ bool) error {
+	return t.Translator.AddRange(key, text, rule, true)
+}
+
+// translatorFor returns the translator of the preferred locale of the request, or the fallback translator
+func (t *Translator) translatorFor(r *http.Request) ut.Translator {
+	if r == nil || t.Resolver == nil {
+		return t.fallback
+	}
+
+	for _, preferred := range t.Resolver(r) {
+		if locale, found := t.findLocale(preferred); found {
+			return t.getTranslator(locale)
+		}
+	}
+
+	return t.fallback
+}
+
+// findLocale returns the supported locale that matches the language tag ("pt-BR", "pt_br", "es-MX", "pt"):
+// the same locale, the base language, or another locale of the same language
+func (t *Translator) findLocale(tag string) (string, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "-", "_")
+	language, _, _ := strings.Cut(tag, "_")
+
+	for _, match := range []func(locale string) bool{
+		func(locale string) bool { return strings.EqualFold(locale, tag) },
+		func(locale string) bool { return strings.EqualFold(locale, language) },
+		func(locale string) bool {
+			return strings.HasPrefix(strings.ToLower(locale), strings.ToLower(language)+"_")
+		},
+	} {
+		if index := slices.IndexFunc(t.locales, match); index >= 0 {
+			return t.locales[index], true
+		}
+	}
+
+	return "", false
+}
+
+// AcceptLanguage is the default LocaleResolver, that returns the languages of the Accept-Language header
+// ordered by their quality
+func AcceptLanguage(r *http.Request) []string {
+	var languages []mediaRange
+
+	for value := range strings.SplitSeq(r.Header.Get("Accept-Language"), ",") {
+		if language := parseMediaRange(value); language.name != "" && language.name != "*" && language.quality > 0 {
+			languages = append(languages, language)
+		}
+	}
+
+	slices.SortStableFunc(languages, func(a, b mediaRange) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+
+	tags := make([]string, len(languages))
+	for i := range languages {
+		tags[i] = languages[i].name
+	}
+
+	return tags
+}
+
+// withTranslator returns the request with the translator of a handler in its context, or the request itself
+// if the translator is nil
+func withTranslator(r *http.Request, translator *Translator) *http.Request {
+	if translator == nil || r == nil {
+		return r
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), translatorKey{}, translator))
+}
+
+// requestTranslator returns the translator of the request: the translator of its handler (WithTranslator)
+// or the translator set by SetTranslator, or nil if the translation is disabled
+func requestTranslator(r *http.Request) ut.Translator {
+	if r != nil {
+		if translator, ok := r.Context().Value(translatorKey{}).(*Translator); ok {
+			return translator.translatorFor(r)
+		}
+	}
+
+	translatorLock.RLock()
+	defer translatorLock.RUnlock()
+
+	if activeTranslator == nil {
+		return nil
+	}
+
+	return activeTranslator.translatorFor(r)
+}
+
+// translateMessage returns the message of the validation error in the locale of the translator
+// Without a translator, or a translation for the rule, it returns the default message
+func translateMessage(fieldError validator.FieldError, trans ut.Translator) string {
+	if trans == nil {
+		return validationMessage(fieldError)
+	}
+
+	translatorLock.RLock()
+	defer translatorLock.RUnlock()
+
+	message := fieldError.Translate(trans)
+	if message == fieldError.Error() {
+		// no translation for the rule
+		return validationMessage(fieldError)
+	}
+
+	return message
+}
+
+// useValidator adds the validator set by WithValidator to the validators that receive the translations,
+// and registers the translations of the active translator into it
+func useValidator(v *validator.Validate) error {
+	translatorLock.Lock()
+	defer translatorLock.Unlock()
+
+	if v == getValidator() || slices.Contains(configValidators, v) {
+		return nil
+	}
+
+	configValidators = append(configValidators, v)
+	if activeTranslator == nil {
+		return nil
+	}
+
+	return activeTranslator.register(v)
+}
+
+// useTranslator registers the translations of the translator set by WithTranslator into the validator
+func useTranslator(translator *Translator, v *validator.Validate) error {
+	if translator == nil {
+		return nil
+	}
+
+	translatorLock.Lock()
+	defer translatorLock.Unlock()
+
+	return translator.register(v)
+}
+
+// Validator returns the validator shared by the request schemas, that have no validator set by WithValidator.
+// Custom validations registered into it apply to every request schema:
+//
+//	_ = typedhandler.Validator().RegisterValidation("cpf", validateCPF)
+//
+// The validations should be registered before the handlers receive requests
+func Validator() *validator.Validate {
+	return getValidator()
+}
+
+// NewValidator creates a validator configured like the shared validator: the "required" rule applies to
+// struct fields, and the validation errors report the names of the fields in the request (RequestFieldName)
+func NewValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(RequestFieldName)
+
+	return v
+}
+
+// getValidator returns the validator shared by the request schemas
+func getValidator() *validator.Validate {
+	sharedValidatorOnce.Do(func() {
+		sharedValidator = NewValidator()
+	})
+
+	return sharedValidator
+}
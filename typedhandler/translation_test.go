@@ -0,0 +1,170 @@
+package typedhandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/pt_BR"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type translatedRequest struct {
+	Name string `query:"name" validate:"required"`
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "fr;q=0.5, pt-BR, es;q=0.8, *;q=0.1, de;q=0")
+
+	assert.Equal(t, []string{"pt-br", "es", "fr"}, AcceptLanguage(r))
+}
+
+func TestTranslator_findLocale(t *testing.T) {
+	t.Parallel()
+
+	translator := NewTranslator(en.New(), pt_BR.New(), es.New())
+	tests := []struct {
+		tag    string
+		locale string
+		found  bool
+	}{
+		{"pt-BR", "pt_BR", true},
+		{"pt_br", "pt_BR", true},
+		{"pt", "pt_BR", true},
+		{"es-MX", "es", true},
+		{"en-US", "en", true},
+		{"fr", "", false},
+	}
+	for _, tt := range tests {
+		locale, found := translator.findLocale(tt.tag)
+		assert.Equal(t, tt.found, found, tt.tag)
+		assert.Equal(t, tt.locale, locale, tt.tag)
+	}
+}
+
+func TestSetTranslator(t *testing.T) { //nolint:paralleltest // changes the global translator
+	translator := NewTranslator(en.New(), pt_BR.New(), es.New(), fr.New()).
+		RegisterTranslations("fr", func(v *validator.Validate, trans ut.Translator) error {
+			return v.RegisterTranslation("required", trans,
+				func(trans ut.Translator) error {
+					return trans.Add("required", "{0} est obligatoire", false)
+				},
+				func(trans ut.Translator, fe validator.FieldError) string {
+					message, _ := trans.T("required", fe.Field())
+					return message
+				})
+		})
+	require.NoError(t, SetTranslator(translator))
+	t.Cleanup(func() { _ = SetTranslator(nil) })
+
+	handler := CreateSimpleHandler(func(context.Context, *translatedRequest) (string, int, error) {
+		return "", http.StatusOK, nil
+	})
+
+	tests := []struct {
+		acceptLanguage string
+		message        string
+	}{
+		{"pt-BR,pt;q=0.9", "name é um campo obrigatório"},
+		{"es-MX", "name es un campo requerido"},
+		{"fr-CA", "name est obligatoire"},
+		{"de", "name is a required field"},
+		{"", "name is a required field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tt.acceptLanguage)
+
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), `"message":"`+tt.message+`"`)
+		})
+	}
+
+	t.Run("config_validator", func(t *testing.T) {
+		handler := CreateSimpleHandler(func(context.Context, *translatedRequest) (string, int, error) {
+			return "", http.StatusOK, nil
+		}, WithValidator(NewValidator()))
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", "pt-BR")
+
+		w := httptest.NewRecorder()
+		handler(w, r)
+		assert.Contains(t, w.Body.String(), `"message":"name é um campo obrigatório"`)
+	})
+	t.Run("custom_resolver", func(t *testing.T) {
+		translator.Resolver = func(r *http.Request) []string {
+			return []string{r.URL.Query().Get("lang")}
+		}
+		t.Cleanup(func() { translator.Resolver = AcceptLanguage })
+
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/?lang=es", nil))
+		assert.Contains(t, w.Body.String(), `"message":"name es un campo requerido"`)
+	})
+}
+
+func TestWithTranslator(t *testing.T) {
+	t.Parallel()
+
+	translator := NewTranslator(en.New(), pt_BR.New())
+	service := func(context.Context, *translatedRequest) (string, int, error) {
+		return "", http.StatusOK, nil
+	}
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", "pt-BR")
+
+		return r
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		CreateSimpleHandler(service, WithTranslator(translator))(w, newRequest())
+		assert.Contains(t, w.Body.String(), `"message":"name é um campo obrigatório"`)
+
+		w = httptest.NewRecorder()
+		CreateSimpleHandler(service)(w, newRequest())
+		assert.Contains(t, w.Body.String(), `"message":"Field validation for 'name' failed on the 'required' tag"`,
+			"the other handlers are not translated")
+	})
+	t.Run("problem_details", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		CreateSimpleHandler(service, WithTranslator(translator), WithProblemDetails(true))(w, newRequest())
+		assert.Contains(t, w.Body.String(), `"message":"name é um campo obrigatório"`)
+	})
+	t.Run("error_renderer", func(t *testing.T) {
+		t.Parallel()
+
+		handler := CreateSimpleHandler(service, WithTranslator(translator),
+			WithErrorRenderer(func(w http.ResponseWriter, r *http.Request, err error) {
+				var validationErrors validator.ValidationErrors
+				if errors.As(err, &validationErrors) {
+					_, _ = w.Write([]byte(NewValidationErrorResponse(r, validationErrors).Error()))
+				}
+			}))
+
+		w := httptest.NewRecorder()
+		handler(w, newRequest())
+		assert.Equal(t, "name é um campo obrigatório", w.Body.String())
+	})
+}
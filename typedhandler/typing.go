@@ -1,6 +1,9 @@
 package typedhandler
 
-import "reflect"
+import (
+	"reflect"
+	"strings"
+)
 
 // mustBeAPointer panics if the type T is not a pointer type.
 func mustBeAPointer[T any]() {
@@ -59,3 +62,47 @@ func areTypesCompatible(t1, t2 reflect.Type) bool {
 
 	return t1 == t2
 }
+
+// isNestedStruct checks if the type is a struct with fields to be bound,
+// and not a struct converted from a single value, like time.Time, Optional, encoding.TextUnmarshaler
+// implementations and types with registered converters.
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && optionalType(t) == nil && converterFor(t, ParseTime) == nil
+}
+
+// embeddedStruct returns the struct type of an embedded field to be flattened,
+// or nil if the field is not an untagged embedded struct (or pointer to an exported struct).
+func embeddedStruct(field *reflect.StructField) reflect.Type {
+	if !field.Anonymous || queryTagName(field) != "" {
+		return nil
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		if !field.IsExported() {
+			// nil pointers to unexported structs can't be allocated
+			return nil
+		}
+
+		t = t.Elem()
+	}
+
+	if !isNestedStruct(t) {
+		return nil
+	}
+
+	return t
+}
+
+// queryTagName returns the name from the "query" tag of the field,
+// or from the "form" tag, when there is no "query" tag.
+func queryTagName(field *reflect.StructField) string {
+	tag := field.Tag.Get("query")
+	if tag == "" {
+		tag = field.Tag.Get("form")
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+
+	return name
+}
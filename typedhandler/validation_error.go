@@ -0,0 +1,270 @@
+package typedhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type (
+	// ValidationErrorResponse is the response body written for validation errors
+	ValidationErrorResponse struct {
+		Errors []FieldValidationError `json:"errors"`
+	}
+
+	// FieldValidationError describes a field that failed validation
+	FieldValidationError struct {
+		Field   string `json:"field"`            // name of the field in the request (json, query, path, header, ...)
+		Source  string `json:"source,omitempty"` // source of the value, for parse errors
+		Rule    string `json:"rule"`             // failing validation rule, or "parse" for parse errors
+		Param   string `json:"param,omitempty"`  // parameter of the rule, e.g. "3" for "min=3"
+		Value   any    `json:"value"`            // rejected value
+		Message string `json:"message"`
+	}
+
+	// requestFieldError is the validation error of a field of an embedded struct, with the namespace of the field
+	// in the request: the values of the embedded structs are bound like the values of the outer struct,
+	// so the names of the embedded structs are removed from the namespace
+	requestFieldError struct {
+		validator.FieldError
+		namespace string
+	}
+
+	// ValidationErrorRenderer writes the validation errors of the request
+	ValidationErrorRenderer func(w http.ResponseWriter, r *http.Request, errs validator.ValidationErrors)
+)
+
+// parseRule is the rule of the parse errors in the lists of field errors
+const parseRule = "parse"
+
+var (
+	validationErrorRenderer     ValidationErrorRenderer = renderValidationErrorResponse
+	validationErrorRendererLock sync.RWMutex
+
+	// requestNameTags are the struct tags with the name of a field in the request, in order of precedence
+	requestNameTags = []string{"json", "query", "path", "header", "cookie", "form", "file"}
+)
+
+// SetValidationErrorRenderer replaces the renderer of validation errors
+// A nil renderer restores the default, which writes a ValidationErrorResponse as JSON.
+// WithValidationErrorRenderer sets the renderer of a handler
+func SetValidationErrorRenderer(renderer ValidationErrorRenderer) {
+	validationErrorRendererLock.Lock()
+	defer validationErrorRendererLock.Unlock()
+
+	if renderer == nil {
+		renderer = renderValidationErrorResponse
+	}
+
+	validationErrorRenderer = renderer
+}
+
+// NewValidationErrorResponse creates the ValidationErrorResponse of the validation errors of the request,
+// with the messages translated into the locale of the request, if a Translator is set
+// by WithTranslator (the renderers of the handler receive it with the request) or SetTranslator
+func NewValidationErrorResponse(r *http.Request, errs validator.ValidationErrors) *ValidationErrorResponse {
+	trans := requestTranslator(r)
+	response := &ValidationErrorResponse{Errors: make([]FieldValidationError, len(errs))}
+	for i, fieldError := range errs {
+		response.Errors[i] = FieldValidationError{
+			Field:   validationFieldName(fieldError),
+			Rule:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Value:   fieldError.Value(),
+			Message: translateMessage(fieldError, trans),
+		}
+	}
+
+	return response
+}
+
+// NewRequestErrorsResponse creates the ValidationErrorResponse of all the errors of a request parsed
+// with AggregateParseErrors. ParseErrors have the "parse" rule, their source and their raw value
+func NewRequestErrorsResponse(r *http.Request, errs RequestErrors) *ValidationErrorResponse {
+	response := &ValidationErrorResponse{}
+
+	for _, err := range errs {
+		var (
+			validateError validator.ValidationErrors
+			parseError    ParseError
+		)
+
+		switch {
+		case errors.As(err, &validateError):
+			response.Errors = append(response.Errors, NewValidationErrorResponse(r, validateError).Errors...)
+		case errors.As(err, &parseError):
+			response.Errors = append(response.Errors, FieldValidationError{
+				Field:   parseError.Param,
+				Source:  parseError.Source,
+				Rule:    parseRule,
+				Value:   parseError.Value,
+				Message: parseError.Error(),
+			})
+		default:
+			response.Errors = append(response.Errors, FieldValidationError{Message: err.Error()})
+		}
+	}
+
+	return response
+}
+
+// Error returns the messages of the validation errors
+func (e *ValidationErrorResponse) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i := range e.Errors {
+		messages[i] = e.Errors[i].Message
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (e *ValidationErrorResponse) Status() int {
+	return http.StatusBadRequest
+}
+
+func (e *ValidationErrorResponse) Json() []byte {
+	body, err := json.Marshal(e)
+	if err != nil {
+		// rejected values that can't be encoded are removed
+		for i := range e.Errors {
+			e.Errors[i].Value = nil
+		}
+
+		body, _ = json.Marshal(e)
+	}
+
+	return body
+}
+
+// writeValidationErrors writes the validation errors with the current renderer
+func writeValidationErrors(w http.ResponseWriter, r *http.Request, errs validator.ValidationErrors) {
+	validationErrorRendererLock.RLock()
+	renderer := validationErrorRenderer
+	validationErrorRendererLock.RUnlock()
+
+	renderer(w, r, errs)
+}
+
+// writeRequestErrors writes all the errors of a request parsed with AggregateParseErrors
+func writeRequestErrors(w http.ResponseWriter, r *http.Request, errs RequestErrors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errs.Status())
+	_, _ = w.Write(NewRequestErrorsResponse(r, errs).Json())
+}
+
+// renderValidationErrorResponse is the default ValidationErrorRenderer
+func renderValidationErrorResponse(w http.ResponseWriter, r *http.Request, errs validator.ValidationErrors) {
+	response := NewValidationErrorResponse(r, errs)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.Status())
+	_, _ = w.Write(response.Json())
+}
+
+// RequestFieldName returns the name of the field in the request, from the first of the requestNameTags,
+// or "" to use the Go field name. It is the tag name function of the validators created by NewValidator,
+// so the validation errors report the names of the fields in the request
+func RequestFieldName(field reflect.StructField) string {
+	for _, tag := range requestNameTags {
+		if value := field.Tag.Get(tag); value != "" {
+			name, _, _ := strings.Cut(value, ",")
+			if name == "-" {
+				return ""
+			}
+
+			return name
+		}
+	}
+
+	return ""
+}
+
+func (e requestFieldError) Namespace() string {
+	return e.namespace
+}
+
+// withRequestNamespaces replaces the validation errors of the fields of embedded structs of the struct type t
+// with requestFieldErrors
+func withRequestNamespaces(err error, t reflect.Type) error {
+	var validateErrors validator.ValidationErrors
+	if !errors.As(err, &validateErrors) {
+		return err
+	}
+
+	for i, fieldError := range validateErrors {
+		if namespace, changed := requestNamespace(fieldError, t); changed {
+			validateErrors[i] = requestFieldError{FieldError: fieldError, namespace: namespace}
+		}
+	}
+
+	return err
+}
+
+// requestNamespace returns the namespace of the validation error without the names of the untagged embedded
+// structs of the struct type t, and true if any name was removed
+func requestNamespace(fieldError validator.FieldError, t reflect.Type) (string, bool) {
+	names := strings.Split(fieldError.Namespace(), ".")
+	goNames := strings.Split(fieldError.StructNamespace(), ".")
+
+	if len(names) != len(goNames) {
+		return "", false
+	}
+
+	kept := []string{names[0]}
+
+	for i := 1; i < len(goNames); i++ {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array ||
+			t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+
+		goName, _, _ := strings.Cut(goNames[i], "[")
+
+		field, found := reflect.StructField{}, false
+		if t.Kind() == reflect.Struct {
+			field, found = t.FieldByName(goName)
+		}
+
+		if !found {
+			kept = append(kept, names[i:]...)
+			break
+		}
+
+		if !field.Anonymous || RequestFieldName(field) != "" {
+			kept = append(kept, names[i])
+		}
+
+		t = field.Type
+	}
+
+	if len(kept) == len(names) {
+		return "", false
+	}
+
+	return strings.Join(kept, "."), true
+}
+
+// validationFieldName returns the path of the field in the request, without the name of the request struct
+// e.g. "address.street" for a field "Street" with json tag "street" of a field "Address" with json tag "address"
+func validationFieldName(fieldError validator.FieldError) string {
+	if _, name, found := strings.Cut(fieldError.Namespace(), "."); found {
+		return name
+	}
+
+	return fieldError.Field()
+}
+
+// validationMessage returns the message of the validation error, without the "Key: '...' Error:" prefix
+func validationMessage(fieldError validator.FieldError) string {
+	message := fieldError.Error()
+	if _, after, found := strings.Cut(message, " Error:"); found {
+		return after
+	}
+
+	return message
+}
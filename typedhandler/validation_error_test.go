@@ -0,0 +1,137 @@
+package typedhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	validationAddress struct {
+		Street string `json:"street" validate:"required"`
+	}
+	validationRequest struct {
+		ID      int               `path:"id"          validate:"gt=0"`
+		Page    int               `query:"page"       validate:"min=1"`
+		Tenant  string            `header:"X-Tenant"  validate:"required"`
+		Email   string            `json:"email"       validate:"email"`
+		Role    string            `json:"role,omitempty" validate:"oneof='a b' c"`
+		Address validationAddress `json:"address"`
+		Secret  string            `json:"-"           validate:"required"`
+	}
+	ValidationPage struct {
+		Page  int `query:"page"  validate:"min=1"`
+		Limit int `query:"limit" validate:"max=100"`
+	}
+	validationEmbeddedRequest struct {
+		ValidationPage
+		Filter struct {
+			ValidationPage
+		} `query:"filter"`
+		Items []struct {
+			*ValidationPage
+		} `json:"items" validate:"dive"`
+	}
+)
+
+func TestNewValidationErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	schemaHelper := GetSchemaHelper[*validationRequest]()
+	err := schemaHelper.validateFunc(t.Context(), &validationRequest{Email: `"quoted"`, Role: "x"})
+
+	var validateErrors validator.ValidationErrors
+	require.ErrorAs(t, err, &validateErrors)
+
+	response := NewValidationErrorResponse(nil, validateErrors)
+	fields := make(map[string]FieldValidationError, len(response.Errors))
+
+	for _, fieldError := range response.Errors {
+		fields[fieldError.Field] = fieldError
+	}
+
+	assert.Len(t, fields, 7)
+	assert.Equal(t, FieldValidationError{
+		Field: "id", Rule: "gt", Param: "0", Value: 0,
+		Message: "Field validation for 'id' failed on the 'gt' tag",
+	}, fields["id"])
+	assert.Equal(t, "min", fields["page"].Rule)
+	assert.Equal(t, "required", fields["X-Tenant"].Rule)
+	assert.Equal(t, `"quoted"`, fields["email"].Value)
+	assert.Equal(t, "'a b' c", fields["role"].Param)
+	assert.Contains(t, fields, "address.street")
+	assert.Contains(t, fields, "Secret") // json:"-" fields use the Go field name
+
+	var decoded ValidationErrorResponse
+	require.NoError(t, json.Unmarshal(response.Json(), &decoded))
+	assert.Len(t, decoded.Errors, 7)
+	assert.Equal(t, http.StatusBadRequest, response.Status())
+	assert.Contains(t, response.Error(), "Field validation for 'email' failed on the 'email' tag")
+}
+
+func TestNewValidationErrorResponse_embedded(t *testing.T) {
+	t.Parallel()
+
+	handler := CreateSimpleHandler(func(context.Context, *validationEmbeddedRequest) (string, int, error) {
+		return "", http.StatusOK, nil
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/?page=0&filter.limit=101",
+		strings.NewReader(`{"items":[{"Page":1},{"Page":0}]}`)))
+
+	var response ValidationErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	fields := make([]string, len(response.Errors))
+	for i, fieldError := range response.Errors {
+		fields[i] = fieldError.Field
+	}
+
+	// the names of the embedded structs are not in the request
+	assert.ElementsMatch(t, []string{"page", "filter.page", "filter.limit", "items[1].page"}, fields)
+	assert.Contains(t, response.Errors[0].Message, "Field validation for 'page' failed on the 'min' tag")
+}
+
+func TestValidationErrorResponse_Json(t *testing.T) {
+	t.Parallel()
+
+	response := &ValidationErrorResponse{Errors: []FieldValidationError{
+		{Field: "callback", Rule: "required", Value: func() {}, Message: "invalid"},
+	}}
+	assert.JSONEq(t,
+		`{"errors":[{"field":"callback","rule":"required","value":null,"message":"invalid"}]}`,
+		string(response.Json()))
+}
+
+func TestSetValidationErrorRenderer(t *testing.T) { //nolint:paralleltest // changes the global renderer
+	t.Cleanup(func() { SetValidationErrorRenderer(nil) })
+
+	SetValidationErrorRenderer(func(w http.ResponseWriter, _ *http.Request, errs validator.ValidationErrors) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(validationFieldName(errs[0])))
+	})
+
+	err := GetSchemaHelper[*validationRequest]().validateFunc(t.Context(), &validationRequest{})
+	require.Error(t, err)
+
+	w := httptest.NewRecorder()
+	writeErrorResponse(w, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "id", w.Body.String())
+
+	SetValidationErrorRenderer(nil)
+
+	w = httptest.NewRecorder()
+	writeErrorResponse(w, nil, errors.Join(errors.New("wrapped"), err))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"id"`)
+}